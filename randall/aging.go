@@ -0,0 +1,254 @@
+package randall
+
+import (
+	"encoding/csv"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// An aging bucket of outstanding invoices, by days past their due date.
+type AgingBucket string
+
+const (
+	AgingCurrent AgingBucket = "current"
+	Aging1To30   AgingBucket = "1-30"
+	Aging31To60  AgingBucket = "31-60"
+	Aging61To90  AgingBucket = "61-90"
+	AgingOver90  AgingBucket = "90+"
+)
+
+// Every aging bucket, from the most recent to the oldest.
+var AgingBuckets = []AgingBucket{AgingCurrent, Aging1To30, Aging31To60, Aging61To90, AgingOver90}
+
+// An invoice with an outstanding balance.
+type AgingInvoice struct {
+	Invoice Invoice
+	// The payments recorded up to the report's date. Only fetched for invoices updated since
+	// that day, the balance of others is their current due amount.
+	Payments    []InvoicePayment
+	Paid        decimal.Decimal
+	Outstanding decimal.Decimal
+	// The number of days past the due date. Zero or negative if the invoice is not due yet.
+	DaysOverdue int
+	Bucket      AgingBucket
+}
+
+// The outstanding balances of a client in one currency.
+type AgingGroup struct {
+	Client   ClientRef
+	Currency Currency
+	// The outstanding balance in every bucket.
+	Buckets  map[AgingBucket]decimal.Decimal
+	Total    decimal.Decimal
+	Invoices []AgingInvoice
+}
+
+// Outstanding invoice balances grouped by client, currency and age.
+type AgingReport struct {
+	AsOf time.Time
+	// The groups, sorted by client name and currency.
+	Groups []AgingGroup
+}
+
+// Builds the accounts receivable aging report as of the day of asOf. Balances are computed
+// from the invoices' amounts and the payments recorded up to asOf, so invoices paid since
+// are reported with the balance they had at the time. Payments are only fetched for invoices
+// updated since asOf, as recording a payment updates its invoice. Draft and closed invoices
+// are ignored.
+func BuildAgingReport(api InvoicesApi, asOf time.Time) (AgingReport, error) {
+	day := time.Date(asOf.Year(), asOf.Month(), asOf.Day(), 0, 0, 0, 0, time.UTC)
+	report := AgingReport{AsOf: day}
+
+	invoices, err := api.GetAllPages(HarvestCollectionParams{State: string(InvoiceStateOpen), To: day})
+
+	if err != nil {
+		return report, err
+	}
+
+	// Invoices paid after asOf were last updated after it as well
+	paid, err := api.GetAllPages(HarvestCollectionParams{State: string(InvoiceStatePaid), To: day, UpdatedSince: day})
+
+	if err != nil {
+		return report, err
+	}
+
+	groups := make(map[string]*AgingGroup)
+
+	for _, invoice := range append(invoices, paid...) {
+		if invoice.IssueDate.After(day) {
+			continue
+		}
+
+		aging := AgingInvoice{Invoice: invoice}
+
+		if invoice.UpdatedAt.Before(day) {
+			aging.Paid = invoice.Amount.Sub(invoice.DueAmount)
+		} else {
+			payments, err := api.GetAllInvoicePaymentPages(invoice.Id)
+
+			if err != nil {
+				return report, err
+			}
+
+			for _, payment := range payments {
+				if paymentDate(payment).After(day) {
+					continue
+				}
+
+				aging.Payments = append(aging.Payments, payment)
+				aging.Paid = aging.Paid.Add(payment.Amount)
+			}
+		}
+
+		aging.Outstanding = invoice.Amount.Sub(aging.Paid)
+
+		if !aging.Outstanding.IsPositive() {
+			continue
+		}
+
+		due := invoice.DueDate.Time
+
+		if due.IsZero() {
+			due = invoice.IssueDate.Time
+		}
+
+		aging.DaysOverdue = int(day.Sub(due) / (24 * time.Hour))
+		aging.Bucket = agingBucket(aging.DaysOverdue)
+
+		key := strconv.FormatUint(uint64(invoice.Client.Id), 10) + "|" + string(invoice.Currency)
+		group, ok := groups[key]
+
+		if !ok {
+			group = &AgingGroup{
+				Client:   invoice.Client,
+				Currency: invoice.Currency,
+				Buckets:  make(map[AgingBucket]decimal.Decimal, len(AgingBuckets)),
+			}
+			groups[key] = group
+		}
+
+		group.Buckets[aging.Bucket] = group.Buckets[aging.Bucket].Add(aging.Outstanding)
+		group.Total = group.Total.Add(aging.Outstanding)
+		group.Invoices = append(group.Invoices, aging)
+	}
+
+	for _, group := range groups {
+		sort.Slice(group.Invoices, func(i, j int) bool {
+			return group.Invoices[i].DaysOverdue > group.Invoices[j].DaysOverdue
+		})
+
+		report.Groups = append(report.Groups, *group)
+	}
+
+	sort.Slice(report.Groups, func(i, j int) bool {
+		if report.Groups[i].Client.Name != report.Groups[j].Client.Name {
+			return report.Groups[i].Client.Name < report.Groups[j].Client.Name
+		}
+
+		return report.Groups[i].Currency < report.Groups[j].Currency
+	})
+
+	return report, nil
+}
+
+// Writes one row per client and currency with the outstanding balance of every bucket.
+func (r AgingReport) WriteCsv(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	header := []string{"client", "currency"}
+
+	for _, bucket := range AgingBuckets {
+		header = append(header, string(bucket))
+	}
+
+	if err := cw.Write(append(header, "total")); err != nil {
+		return err
+	}
+
+	for _, group := range r.Groups {
+		row := []string{group.Client.Name, group.Currency.String()}
+
+		for _, bucket := range AgingBuckets {
+			row = append(row, formatCurrencyAmount(group.Buckets[bucket], group.Currency))
+		}
+
+		if err := cw.Write(append(row, formatCurrencyAmount(group.Total, group.Currency))); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// Writes one row per outstanding invoice.
+func (r AgingReport) WriteInvoicesCsv(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	header := []string{"client", "currency", "invoice_id", "number", "issue_date", "due_date", "amount", "paid", "outstanding", "days_overdue", "bucket"}
+
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+
+	for _, group := range r.Groups {
+		for _, inv := range group.Invoices {
+			row := []string{
+				group.Client.Name,
+				group.Currency.String(),
+				strconv.FormatUint(uint64(inv.Invoice.Id), 10),
+				inv.Invoice.Number,
+				inv.Invoice.IssueDate.Format("2006-01-02"),
+				inv.Invoice.DueDate.Format("2006-01-02"),
+				formatCurrencyAmount(inv.Invoice.Amount, group.Currency),
+				formatCurrencyAmount(inv.Paid, group.Currency),
+				formatCurrencyAmount(inv.Outstanding, group.Currency),
+				strconv.Itoa(inv.DaysOverdue),
+				string(inv.Bucket),
+			}
+
+			if err := cw.Write(row); err != nil {
+				return err
+			}
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+func agingBucket(daysOverdue int) AgingBucket {
+	switch {
+	case daysOverdue <= 0:
+		return AgingCurrent
+	case daysOverdue <= 30:
+		return Aging1To30
+	case daysOverdue <= 60:
+		return Aging31To60
+	case daysOverdue <= 90:
+		return Aging61To90
+	default:
+		return AgingOver90
+	}
+}
+
+func paymentDate(p InvoicePayment) time.Time {
+	if !p.PaidDate.IsZero() || p.PaidAt == nil {
+		return p.PaidDate.Time
+	}
+
+	return time.Date(p.PaidAt.Year(), p.PaidAt.Month(), p.PaidAt.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// Formats amount with the minor units of the currency, or as is for unknown currencies.
+func formatCurrencyAmount(amount decimal.Decimal, currency Currency) string {
+	units, err := CurrencyMinorUnits(currency)
+
+	if err != nil {
+		return amount.String()
+	}
+
+	return amount.StringFixed(units)
+}
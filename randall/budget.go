@@ -0,0 +1,264 @@
+package randall
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Returned by BudgetTracker.Track for projects that are not budgeted.
+var ErrNoBudget = errors.New("the project has no budget")
+
+// The unit a project budget is measured in.
+type BudgetUnit string
+
+const (
+	BudgetUnitHours BudgetUnit = "hours"
+	BudgetUnitFees  BudgetUnit = "fees"
+)
+
+// The consumption of a budget: the project's total budget, or the budget of a task or
+// person for projects budgeted by task or person.
+type BudgetLine struct {
+	// The task of the line when budgeting by task. Nil otherwise.
+	Task *ObjectRef
+	// The user of the line when budgeting by person. Nil otherwise.
+	User *ObjectRef
+	// The budget, in hours or in the client's currency depending on the budget's unit.
+	Budget    decimal.Decimal
+	Spent     decimal.Decimal
+	Remaining decimal.Decimal
+	// Spent as a percentage of the budget. Zero if the budget is zero.
+	PercentSpent decimal.Decimal
+	OverBudget   bool
+	// Whether PercentSpent reached the project's over budget notification percentage.
+	NotificationThresholdReached bool
+	// The average amount spent per day since the start of the budget period.
+	BurnRate decimal.Decimal
+	// The date the budget runs out at the current burn rate. Nil if nothing was spent
+	// yet, if the budget already ran out, or for monthly budgets that last until the end
+	// of the month.
+	ProjectedExhaustion *time.Time
+}
+
+// The budget consumption of a project as of a date.
+type ProjectBudget struct {
+	Project Project
+	Unit    BudgetUnit
+	// The first day of the budget period: the first day of the month for monthly budgets,
+	// otherwise the project's start date, or the date of the first time entry if unset.
+	PeriodStart time.Time
+	// The day consumption is computed as of.
+	AsOf time.Time
+	// Whether billable expenses count towards a fees budget.
+	ExpensesIncluded bool
+	// The consumption of the project's total budget. For projects budgeted by task or
+	// person the budget is the sum of the lines' budgets.
+	Total BudgetLine
+	// The consumption of the budget of every budgeted task or person, for projects
+	// budgeted by task or person.
+	Lines []BudgetLine
+}
+
+// Computes the budget consumption of projects from their time entries, expenses and
+// task and user assignments.
+type BudgetTracker struct {
+	client *HarvestClient
+}
+
+func NewBudgetTracker(client *HarvestClient) *BudgetTracker {
+	return &BudgetTracker{client: client}
+}
+
+// Computes the budget consumption of a project as of the day of asOf, following the
+// project's budget_by mode. Monthly budgets only count the month of asOf. Returns
+// ErrNoBudget if the project is not budgeted.
+func (t *BudgetTracker) Track(projectId uint, asOf time.Time) (ProjectBudget, error) {
+	var project Project
+	resp, err := t.client.Projects.Get(projectId)
+
+	if err := DecodeResponse(resp, err, &project); err != nil {
+		return ProjectBudget{}, err
+	}
+
+	day := time.Date(asOf.Year(), asOf.Month(), asOf.Day(), 0, 0, 0, 0, time.UTC)
+	budget := ProjectBudget{Project: project, AsOf: day, Unit: BudgetUnitHours}
+
+	switch project.BudgetBy {
+	case ProjectBudgetByTotalProjectFees, ProjectBudgetByFeesPerTask:
+		budget.Unit = BudgetUnitFees
+		budget.ExpensesIncluded = project.BudgetBy == ProjectBudgetByTotalProjectFees && project.CostBudgetIncludeExpenses
+	case ProjectBudgetByHoursPerProject, ProjectBudgetByHrsPerTask, ProjectBudgetByHrsPerPerson:
+	default:
+		return budget, fmt.Errorf("project %d: %w", projectId, ErrNoBudget)
+	}
+
+	params := GetTimeEntriesParams{ProjectId: int(projectId), ToDate: OptionalTime(day)}
+
+	if project.BudgetIsMonthly {
+		budget.PeriodStart = time.Date(day.Year(), day.Month(), 1, 0, 0, 0, 0, time.UTC)
+		params.FromDate = OptionalTime(budget.PeriodStart)
+	} else if !project.StartsOn.IsZero() {
+		budget.PeriodStart = project.StartsOn.Time
+	}
+
+	entries, err := t.client.TimeEntries.GetAllPages(params)
+
+	if err != nil {
+		return budget, err
+	}
+
+	var expenses []Expense
+
+	if budget.ExpensesIncluded {
+		expenses, err = t.client.Expenses.GetAllPages(HarvestCollectionParams{
+			ProjectId: OptionalUInt(projectId),
+			From:      budget.PeriodStart,
+			To:        day,
+		})
+
+		if err != nil {
+			return budget, err
+		}
+	}
+
+	if budget.PeriodStart.IsZero() {
+		budget.PeriodStart = day
+
+		for _, e := range entries {
+			if e.SpentDate.Before(budget.PeriodStart) {
+				budget.PeriodStart = e.SpentDate.Time
+			}
+		}
+	}
+
+	spent := func(e TimeEntry) decimal.Decimal {
+		if budget.Unit == BudgetUnitFees {
+			return e.billableAmount()
+		}
+
+		return e.Hours
+	}
+
+	total := decimal.Zero
+
+	for _, e := range entries {
+		total = total.Add(spent(e))
+	}
+
+	for _, e := range expenses {
+		if e.Billable {
+			total = total.Add(e.TotalCost)
+		}
+	}
+
+	switch project.BudgetBy {
+	case ProjectBudgetByHoursPerProject:
+		budget.Total.Budget = optionalDecimalOrZero(project.Budget)
+	case ProjectBudgetByTotalProjectFees:
+		budget.Total.Budget = optionalDecimalOrZero(project.CostBudget)
+	case ProjectBudgetByHrsPerTask, ProjectBudgetByFeesPerTask:
+		assignments, err := t.client.Projects.GetAllTaskAssignmentPagesForProject(projectId)
+
+		if err != nil {
+			return budget, err
+		}
+
+		for _, a := range assignments {
+			if a.Budget == nil {
+				continue
+			}
+
+			task := a.Task
+			line := BudgetLine{Task: &task, Budget: *a.Budget}
+
+			for _, e := range entries {
+				if e.Task.Id == task.Id {
+					line.Spent = line.Spent.Add(spent(e))
+				}
+			}
+
+			budget.Lines = append(budget.Lines, line)
+			budget.Total.Budget = budget.Total.Budget.Add(line.Budget)
+		}
+	case ProjectBudgetByHrsPerPerson:
+		assignments, err := t.client.Projects.GetAllUserAssignmentPagesForProject(projectId)
+
+		if err != nil {
+			return budget, err
+		}
+
+		for _, a := range assignments {
+			if a.Budget == nil {
+				continue
+			}
+
+			user := a.User
+			line := BudgetLine{User: &user, Budget: *a.Budget}
+
+			for _, e := range entries {
+				if e.User.Id == user.Id {
+					line.Spent = line.Spent.Add(spent(e))
+				}
+			}
+
+			budget.Lines = append(budget.Lines, line)
+			budget.Total.Budget = budget.Total.Budget.Add(line.Budget)
+		}
+	}
+
+	budget.Total.Spent = total
+	budget.Total = budget.complete(budget.Total)
+
+	for i := range budget.Lines {
+		budget.Lines[i] = budget.complete(budget.Lines[i])
+	}
+
+	return budget, nil
+}
+
+// Computes the derived values of a line from its budget and spent amount.
+func (b ProjectBudget) complete(line BudgetLine) BudgetLine {
+	line.Remaining = line.Budget.Sub(line.Spent)
+	line.OverBudget = line.Remaining.IsNegative()
+
+	if line.Budget.IsPositive() {
+		line.PercentSpent = line.Spent.Div(line.Budget).Mul(decimal.NewFromInt(100)).Round(2)
+	}
+
+	if threshold := b.Project.OverBudgetNotificationPercentage; threshold != nil && line.Budget.IsPositive() {
+		line.NotificationThresholdReached = line.PercentSpent.GreaterThanOrEqual(*threshold)
+	}
+
+	days := int64(b.AsOf.Sub(b.PeriodStart)/(24*time.Hour)) + 1
+
+	if days < 1 || !line.Spent.IsPositive() {
+		return line
+	}
+
+	line.BurnRate = line.Spent.Div(decimal.NewFromInt(days))
+
+	if !line.Remaining.IsPositive() {
+		return line
+	}
+
+	daysLeft := line.Remaining.Div(line.BurnRate).Ceil().IntPart()
+	exhaustion := b.AsOf.AddDate(0, 0, int(daysLeft))
+
+	if b.Project.BudgetIsMonthly && !exhaustion.Before(b.PeriodStart.AddDate(0, 1, 0)) {
+		return line
+	}
+
+	line.ProjectedExhaustion = &exhaustion
+	return line
+}
+
+func optionalDecimalOrZero(d *decimal.Decimal) decimal.Decimal {
+	if d == nil {
+		return decimal.Zero
+	}
+
+	return *d
+}
@@ -9,17 +9,24 @@ type ClientsApi struct {
 }
 
 type CreateClientRequest struct {
-	Name     string  `json:"name"`
-	IsActive *bool   `json:"is_active,omitempty"`
-	Address  *string `json:"address,omitempty"`
-	Currency *string `json:"currency,omitempty"`
+	Name     string    `json:"name"`
+	IsActive *bool     `json:"is_active,omitempty"`
+	Address  *string   `json:"address,omitempty"`
+	Currency *Currency `json:"currency,omitempty"`
 }
 
 type PatchClientRequest struct {
-	Name     *string `json:"name,omitempty"`
-	IsActive *bool   `json:"is_active,omitempty"`
-	Address  *string `json:"address,omitempty"`
-	Currency *string `json:"currency,omitempty"`
+	Name     *string   `json:"name,omitempty"`
+	IsActive *bool     `json:"is_active,omitempty"`
+	Address  *string   `json:"address,omitempty"`
+	Currency *Currency `json:"currency,omitempty"`
+}
+
+// A minimal representation of a client embedded in a Harvest payload.
+type ClientRef struct {
+	Id       uint     `json:"id"`
+	Name     string   `json:"name"`
+	Currency Currency `json:"currency"`
 }
 
 func newClientsV2(client *internalClient) ClientsApi {
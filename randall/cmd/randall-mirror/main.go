@@ -0,0 +1,94 @@
+// Command randall-mirror pulls a Harvest account into a local SQLite database and keeps it up
+// to date on every run.
+//
+// Credentials are read from the HARVEST_ACCOUNT_ID, HARVEST_ACCESS_TOKEN, USER_AGENT_APP and
+// USER_AGENT_EMAIL environment variables.
+//
+// Usage:
+//
+//	randall-mirror [-db harvest.db] [-reconcile-days 90] [resource ...]
+package main
+
+import (
+	"database/sql"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/calexa22/randall"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func main() {
+	dbPath := flag.String("db", "harvest.db", "path of the SQLite database")
+	reconcileDays := flag.Int("reconcile-days", 0,
+		"remove time entries, expenses, invoices and estimates deleted in Harvest within this many past days")
+	flag.Parse()
+
+	client, err := newClientFromEnv()
+
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	db, err := sql.Open("sqlite3", *dbPath)
+
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	defer db.Close()
+
+	mirror := randall.NewMirror(client, db)
+
+	if err := mirror.Init(); err != nil {
+		log.Fatal(err)
+	}
+
+	var resources []randall.SyncResource
+
+	for _, arg := range flag.Args() {
+		resources = append(resources, randall.SyncResource(arg))
+	}
+
+	if err := mirror.Refresh(resources...); err != nil {
+		log.Fatal(err)
+	}
+
+	if *reconcileDays > 0 {
+		to := time.Now()
+		from := to.AddDate(0, 0, -*reconcileDays)
+
+		dated := []randall.SyncResource{
+			randall.SyncTimeEntries,
+			randall.SyncExpenses,
+			randall.SyncInvoices,
+			randall.SyncEstimates,
+		}
+
+		for _, resource := range dated {
+			if err := mirror.Reconcile(resource, from, to); err != nil {
+				log.Fatal(err)
+			}
+		}
+	}
+}
+
+func newClientFromEnv() (*randall.HarvestClient, error) {
+	keys := []string{"HARVEST_ACCOUNT_ID", "HARVEST_ACCESS_TOKEN", "USER_AGENT_APP", "USER_AGENT_EMAIL"}
+	values := make([]string, len(keys))
+
+	for i, key := range keys {
+		v, exists := os.LookupEnv(key)
+
+		if !exists || v == "" {
+			return nil, fmt.Errorf("environment variable %s is not set", key)
+		}
+
+		values[i] = v
+	}
+
+	return randall.NewClient(values[0], values[1], values[2], values[3]), nil
+}
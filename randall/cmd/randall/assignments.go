@@ -0,0 +1,140 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/calexa22/randall"
+)
+
+func runProjects(a *app, args []string) error {
+	flags := flag.NewFlagSet("projects", flag.ExitOnError)
+	flags.Parse(args)
+
+	assignments, err := a.client.Users.GetMyActiveProjectAssignmentPages()
+
+	if err != nil {
+		return err
+	}
+
+	rows := make([][]string, 0, len(assignments))
+
+	for _, pa := range assignments {
+		rows = append(rows, []string{
+			strconv.FormatUint(uint64(pa.Project.Id), 10),
+			pa.Project.Code,
+			pa.Project.Name,
+			pa.Client.Name,
+		})
+	}
+
+	return a.print(assignments, []string{"ID", "CODE", "PROJECT", "CLIENT"}, rows)
+}
+
+func runTasks(a *app, args []string) error {
+	flags := flag.NewFlagSet("tasks", flag.ExitOnError)
+	project := flags.String("project", "", "only list the tasks of this project (id, code or name)")
+	flags.Parse(args)
+
+	assignments, err := a.client.Users.GetMyActiveProjectAssignmentPages()
+
+	if err != nil {
+		return err
+	}
+
+	if *project != "" {
+		pa, err := findProjectAssignment(assignments, *project)
+
+		if err != nil {
+			return err
+		}
+
+		assignments = []randall.ProjectAssignment{*pa}
+	}
+
+	type projectTask struct {
+		Project randall.ProjectRef            `json:"project"`
+		Task    randall.ProjectTaskAssignment `json:"task_assignment"`
+	}
+
+	var tasks []projectTask
+	var rows [][]string
+
+	for _, pa := range assignments {
+		for _, ta := range pa.TaskAssignments {
+			if !ta.IsActive {
+				continue
+			}
+
+			tasks = append(tasks, projectTask{Project: pa.Project, Task: ta})
+			rows = append(rows, []string{
+				pa.Project.Name,
+				strconv.FormatUint(uint64(ta.Task.Id), 10),
+				ta.Task.Name,
+				strconv.FormatBool(ta.Billable),
+			})
+		}
+	}
+
+	return a.print(tasks, []string{"PROJECT", "TASK ID", "TASK", "BILLABLE"}, rows)
+}
+
+// Resolves the project and task the user is assigned to. project is matched against the
+// project's id, code and name, task against the task's id and name, ignoring case.
+func (a *app) resolveAssignment(project, task string) (uint, uint, error) {
+	if project == "" || task == "" {
+		return 0, 0, fmt.Errorf("-project and -task are required")
+	}
+
+	assignments, err := a.client.Users.GetMyActiveProjectAssignmentPages()
+
+	if err != nil {
+		return 0, 0, err
+	}
+
+	pa, err := findProjectAssignment(assignments, project)
+
+	if err != nil {
+		return 0, 0, err
+	}
+
+	for _, ta := range pa.TaskAssignments {
+		if ta.IsActive && matchesRef(task, ta.Task.Id, ta.Task.Name) {
+			return pa.Project.Id, ta.Task.Id, nil
+		}
+	}
+
+	return 0, 0, fmt.Errorf("task %q is not assigned to project %s", task, pa.Project.Name)
+}
+
+func findProjectAssignment(assignments []randall.ProjectAssignment, project string) (*randall.ProjectAssignment, error) {
+	var found *randall.ProjectAssignment
+
+	for i, pa := range assignments {
+		if !matchesRef(project, pa.Project.Id, pa.Project.Code) && !strings.EqualFold(project, pa.Project.Name) {
+			continue
+		}
+
+		if found != nil && found.Project.Id != pa.Project.Id {
+			return nil, fmt.Errorf("project %q is ambiguous, use its id", project)
+		}
+
+		found = &assignments[i]
+	}
+
+	if found == nil {
+		return nil, fmt.Errorf("no project %q is assigned to you", project)
+	}
+
+	return found, nil
+}
+
+func matchesRef(s string, id uint, name string) bool {
+	if n, err := strconv.ParseUint(s, 10, 64); err == nil && uint(n) == id {
+		return true
+	}
+
+	return name != "" && strings.EqualFold(s, name)
+}
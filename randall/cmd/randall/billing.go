@@ -0,0 +1,229 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strconv"
+
+	"github.com/calexa22/randall"
+	"github.com/shopspring/decimal"
+)
+
+func runExpense(a *app, args []string) error {
+	flags := flag.NewFlagSet("expense", flag.ExitOnError)
+	project := flags.String("project", "", "project id, code or name")
+	category := flags.String("category", "", "expense category id or name")
+	cost := flags.String("cost", "", "the total cost")
+	units := flags.Uint("units", 0, "the number of units, for unit based expense categories")
+	date := flags.String("date", "", "the spent date as YYYY-MM-DD, defaults to today")
+	notes := flags.String("notes", "", "notes of the expense")
+	receipt := flags.String("receipt", "", "path of a receipt to attach (pdf, png, jpg or gif)")
+	billable := flags.Bool("billable", true, "whether the expense is billable")
+	flags.Parse(args)
+
+	if *project == "" || *category == "" {
+		return fmt.Errorf("-project and -category are required")
+	}
+
+	if (*cost == "") == (*units == 0) {
+		return fmt.Errorf("exactly one of -cost and -units is required")
+	}
+
+	spent, err := parseDate(*date)
+
+	if err != nil {
+		return err
+	}
+
+	assignments, err := a.client.Users.GetMyActiveProjectAssignmentPages()
+
+	if err != nil {
+		return err
+	}
+
+	pa, err := findProjectAssignment(assignments, *project)
+
+	if err != nil {
+		return err
+	}
+
+	categoryId, err := a.resolveExpenseCategory(*category)
+
+	if err != nil {
+		return err
+	}
+
+	req := randall.CreateExpenseRequest{
+		ProjectId:         pa.Project.Id,
+		ExpenseCategoryId: categoryId,
+		SpentDate:         spent,
+		Billable:          randall.OptionalBool(*billable),
+	}
+
+	if *cost != "" {
+		total, err := decimal.NewFromString(*cost)
+
+		if err != nil {
+			return fmt.Errorf("invalid cost %q", *cost)
+		}
+
+		req.TotalCost = randall.OptionalDecimal(total)
+	} else {
+		req.Units = randall.OptionalUInt(*units)
+	}
+
+	if *notes != "" {
+		req.Notes = randall.OptionalString(*notes)
+	}
+
+	if *receipt != "" {
+		req.Receipt = randall.OptionalString(*receipt)
+	}
+
+	var expense randall.Expense
+	resp, err := a.client.Expenses.Create(req)
+
+	if err := randall.DecodeResponse(resp, err, &expense); err != nil {
+		return err
+	}
+
+	receiptName := ""
+
+	if expense.Receipt != nil {
+		receiptName = expense.Receipt.FileName
+	}
+
+	return a.print(expense, []string{"ID", "DATE", "PROJECT", "CATEGORY", "TOTAL", "RECEIPT"}, [][]string{{
+		strconv.FormatUint(uint64(expense.Id), 10),
+		expense.SpentDate.Format("2006-01-02"),
+		expense.Project.Name,
+		expense.ExpenseCategory.Name,
+		expense.TotalCost.StringFixed(2) + " " + expense.Client.Currency.String(),
+		receiptName,
+	}})
+}
+
+func (a *app) resolveExpenseCategory(category string) (uint, error) {
+	var categories struct {
+		ExpenseCategories []randall.ObjectRef `json:"expense_categories"`
+	}
+
+	resp, err := a.client.Expenses.GetAllExpenseCategories(randall.HarvestCollectionParams{
+		IsActive: randall.OptionalBool(true),
+		PerPage:  randall.OptionalInt(2000),
+	})
+
+	if err := randall.DecodeResponse(resp, err, &categories); err != nil {
+		return 0, err
+	}
+
+	for _, c := range categories.ExpenseCategories {
+		if matchesRef(category, c.Id, c.Name) {
+			return c.Id, nil
+		}
+	}
+
+	return 0, fmt.Errorf("no active expense category %q", category)
+}
+
+func runInvoices(a *app, args []string) error {
+	flags := flag.NewFlagSet("invoices", flag.ExitOnError)
+	state := flags.String("state", "", "only list invoices in this state: draft, open, paid or closed")
+	clientId := flags.Uint("client", 0, "only list invoices of this client id")
+	flags.Parse(args)
+
+	var params randall.HarvestCollectionParams
+
+	if *state != "" {
+		invoiceState, err := randall.ParseInvoiceState(*state)
+
+		if err != nil {
+			return err
+		}
+
+		params.State = invoiceState.String()
+	}
+
+	if *clientId != 0 {
+		params.ClientId = randall.OptionalUInt(*clientId)
+	}
+
+	invoices, err := a.client.Invoices.GetAllPages(params)
+
+	if err != nil {
+		return err
+	}
+
+	rows := make([][]string, 0, len(invoices))
+
+	for _, inv := range invoices {
+		rows = append(rows, []string{
+			strconv.FormatUint(uint64(inv.Id), 10),
+			inv.Number,
+			inv.Client.Name,
+			inv.State.String(),
+			inv.IssueDate.Format("2006-01-02"),
+			inv.DueDate.Format("2006-01-02"),
+			inv.Amount.StringFixed(2),
+			inv.DueAmount.StringFixed(2),
+			inv.Currency.String(),
+		})
+	}
+
+	header := []string{"ID", "NUMBER", "CLIENT", "STATE", "ISSUED", "DUE", "AMOUNT", "DUE AMOUNT", "CURRENCY"}
+	return a.print(invoices, header, rows)
+}
+
+func runSend(a *app, args []string) error {
+	flags := flag.NewFlagSet("send", flag.ExitOnError)
+	id := flags.Uint("id", 0, "the invoice to send")
+	to := flags.String("to", "", "comma separated recipient email addresses")
+	subject := flags.String("subject", "", "the subject of the message, defaults to Harvest's")
+	body := flags.String("body", "", "the body of the message, defaults to Harvest's")
+	attachPdf := flags.Bool("attach-pdf", false, "attach the invoice as a PDF")
+	sendCopy := flags.Bool("copy", false, "send me a copy of the message")
+	flags.Parse(args)
+
+	recipients := splitList(*to)
+
+	if *id == 0 || len(recipients) == 0 {
+		return fmt.Errorf("-id and -to are required")
+	}
+
+	req := randall.CreateInvoiceMessageRequest{
+		AttachPdf:   randall.OptionalBool(*attachPdf),
+		SendMeACopy: randall.OptionalBool(*sendCopy),
+	}
+
+	for _, email := range recipients {
+		req.Recipients = append(req.Recipients, randall.MessageRecipient{Email: email})
+	}
+
+	if *subject != "" {
+		req.Subject = randall.OptionalString(*subject)
+	}
+
+	if *body != "" {
+		req.Body = randall.OptionalString(*body)
+	}
+
+	var message struct {
+		Id         uint                       `json:"id"`
+		SentBy     string                     `json:"sent_by"`
+		Subject    string                     `json:"subject"`
+		Recipients []randall.MessageRecipient `json:"recipients"`
+	}
+
+	resp, err := a.client.Invoices.CreateInvoiceMessage(*id, req)
+
+	if err := randall.DecodeResponse(resp, err, &message); err != nil {
+		return err
+	}
+
+	return a.print(message, []string{"MESSAGE ID", "INVOICE ID", "SUBJECT", "RECIPIENTS"}, [][]string{{
+		strconv.FormatUint(uint64(message.Id), 10),
+		strconv.FormatUint(uint64(*id), 10),
+		message.Subject,
+		fmt.Sprint(len(message.Recipients)),
+	}})
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// The credentials randall authenticates with.
+type config struct {
+	AccountId      string `json:"account_id"`
+	AccessToken    string `json:"access_token"`
+	UserAgentApp   string `json:"user_agent_app"`
+	UserAgentEmail string `json:"user_agent_email"`
+}
+
+// Reads the config file at path, or the default config file if path is empty, and
+// overrides its values with the environment variables that are set. A missing default
+// config file is not an error.
+func loadConfig(path string) (config, error) {
+	var cfg config
+
+	explicit := path != ""
+
+	if !explicit {
+		path = defaultConfigPath()
+	}
+
+	if path != "" {
+		b, err := os.ReadFile(path)
+
+		switch {
+		case err == nil:
+			if err := json.Unmarshal(b, &cfg); err != nil {
+				return cfg, fmt.Errorf("config file %s: %w", path, err)
+			}
+		case errors.Is(err, os.ErrNotExist) && !explicit:
+		default:
+			return cfg, err
+		}
+	}
+
+	values := []struct {
+		key   string
+		value *string
+	}{
+		{"HARVEST_ACCOUNT_ID", &cfg.AccountId},
+		{"HARVEST_ACCESS_TOKEN", &cfg.AccessToken},
+		{"USER_AGENT_APP", &cfg.UserAgentApp},
+		{"USER_AGENT_EMAIL", &cfg.UserAgentEmail},
+	}
+
+	for _, v := range values {
+		if env, exists := os.LookupEnv(v.key); exists && env != "" {
+			*v.value = env
+		}
+
+		if *v.value == "" {
+			return cfg, fmt.Errorf("environment variable %s is not set and missing from the config file", v.key)
+		}
+	}
+
+	return cfg, nil
+}
@@ -0,0 +1,150 @@
+// Command randall is a command-line tool for everyday Harvest operations: tracking time,
+// browsing assignments, submitting expenses and sending invoices.
+//
+// Credentials are read from the HARVEST_ACCOUNT_ID, HARVEST_ACCESS_TOKEN, USER_AGENT_APP and
+// USER_AGENT_EMAIL environment variables. Variables that are not set fall back to a JSON
+// config file, by default randall/config.json in the user's config directory:
+//
+//	{
+//		"account_id": "123456",
+//		"access_token": "...",
+//		"user_agent_app": "randall",
+//		"user_agent_email": "me@example.com"
+//	}
+//
+// Usage:
+//
+//	randall [-config path] [-json] <command> [flags]
+//
+// Commands:
+//
+//	start    -project <id|code|name> -task <id|name> [-notes text]   start a timer
+//	start    -id <time entry id>                                      restart a stopped timer
+//	stop     [-id <time entry id>]                                    stop the running timer
+//	log      -project ... -task ... -hours <1.5|1:30> [-date] [-notes]
+//	today                                                             list today's time entries
+//	week                                                              list this week's time entries
+//	projects                                                          list the projects assigned to me
+//	tasks    [-project <id|code|name>]                                list the tasks assigned to me
+//	expense  -project ... -category <id|name> (-cost <amount> | -units <n>) [-date] [-notes] [-receipt file]
+//	invoices [-state draft|open|paid|closed] [-client id]             list invoices
+//	send     -id <invoice id> -to <email,...> [-subject] [-body] [-attach-pdf] [-copy]
+//
+// Output is written as a table, or as JSON with -json.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/calexa22/randall"
+)
+
+type app struct {
+	client *randall.HarvestClient
+	json   bool
+}
+
+type command struct {
+	run   func(a *app, args []string) error
+	usage string
+}
+
+var commands = map[string]command{
+	"start":    {runStart, "start or restart a timer"},
+	"stop":     {runStop, "stop the running timer"},
+	"log":      {runLog, "log time by duration"},
+	"today":    {runToday, "list today's time entries"},
+	"week":     {runWeek, "list this week's time entries"},
+	"projects": {runProjects, "list the projects assigned to me"},
+	"tasks":    {runTasks, "list the tasks assigned to me"},
+	"expense":  {runExpense, "create an expense, optionally with a receipt"},
+	"invoices": {runInvoices, "list invoices"},
+	"send":     {runSend, "send an invoice to its recipients"},
+}
+
+func main() {
+	flags := flag.NewFlagSet("randall", flag.ExitOnError)
+	configPath := flags.String("config", "", "path of the JSON config file")
+	jsonOutput := flags.Bool("json", false, "write output as JSON instead of a table")
+	flags.Usage = func() { usage(flags) }
+	flags.Parse(os.Args[1:])
+
+	if flags.NArg() == 0 {
+		usage(flags)
+		os.Exit(2)
+	}
+
+	cmd, ok := commands[flags.Arg(0)]
+
+	if !ok {
+		fmt.Fprintf(os.Stderr, "randall: unknown command %q\n", flags.Arg(0))
+		usage(flags)
+		os.Exit(2)
+	}
+
+	cfg, err := loadConfig(*configPath)
+
+	if err != nil {
+		fatal(err)
+	}
+
+	a := &app{
+		client: randall.NewClient(cfg.AccountId, cfg.AccessToken, cfg.UserAgentApp, cfg.UserAgentEmail),
+		json:   *jsonOutput,
+	}
+
+	if err := cmd.run(a, flags.Args()[1:]); err != nil {
+		fatal(err)
+	}
+}
+
+func usage(flags *flag.FlagSet) {
+	fmt.Fprintf(os.Stderr, "Usage: randall [-config path] [-json] <command> [flags]\n\nCommands:\n")
+
+	names := make([]string, 0, len(commands))
+
+	for name := range commands {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		fmt.Fprintf(os.Stderr, "  %-10s %s\n", name, commands[name].usage)
+	}
+
+	fmt.Fprintf(os.Stderr, "\nRun randall <command> -h for the flags of a command.\n\nGlobal flags:\n")
+	flags.PrintDefaults()
+}
+
+func fatal(err error) {
+	fmt.Fprintf(os.Stderr, "randall: %v\n", err)
+	os.Exit(1)
+}
+
+func defaultConfigPath() string {
+	dir, err := os.UserConfigDir()
+
+	if err != nil {
+		return ""
+	}
+
+	return filepath.Join(dir, "randall", "config.json")
+}
+
+func splitList(s string) []string {
+	var values []string
+
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+
+	return values
+}
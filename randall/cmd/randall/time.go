@@ -0,0 +1,267 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/calexa22/randall"
+	"github.com/shopspring/decimal"
+)
+
+func runStart(a *app, args []string) error {
+	flags := flag.NewFlagSet("start", flag.ExitOnError)
+	id := flags.Uint("id", 0, "restart this stopped time entry instead of starting a new timer")
+	project := flags.String("project", "", "project id, code or name")
+	task := flags.String("task", "", "task id or name")
+	notes := flags.String("notes", "", "notes of the time entry")
+	flags.Parse(args)
+
+	var entry randall.TimeEntry
+
+	if *id != 0 {
+		resp, err := a.client.TimeEntries.RestartTimeEntry(*id)
+
+		if err := randall.DecodeResponse(resp, err, &entry); err != nil {
+			return err
+		}
+
+		return a.printTimeEntries([]randall.TimeEntry{entry})
+	}
+
+	projectId, taskId, err := a.resolveAssignment(*project, *task)
+
+	if err != nil {
+		return err
+	}
+
+	// Without hours Harvest starts a timer for the new time entry
+	req := randall.CreateTimeEntryViaDurationRequest{
+		ProjectId: projectId,
+		TaskId:    taskId,
+		SpentDate: spentDate(time.Now()),
+	}
+
+	if *notes != "" {
+		req.Notes = randall.OptionalString(*notes)
+	}
+
+	resp, err := a.client.TimeEntries.CreateViaDuration(req)
+
+	if err := randall.DecodeResponse(resp, err, &entry); err != nil {
+		return err
+	}
+
+	return a.printTimeEntries([]randall.TimeEntry{entry})
+}
+
+func runStop(a *app, args []string) error {
+	flags := flag.NewFlagSet("stop", flag.ExitOnError)
+	id := flags.Uint("id", 0, "the time entry to stop, defaults to my running timer")
+	flags.Parse(args)
+
+	timeEntryId := *id
+
+	if timeEntryId == 0 {
+		me, err := a.me()
+
+		if err != nil {
+			return err
+		}
+
+		running, err := a.client.TimeEntries.GetAllPages(randall.GetTimeEntriesParams{
+			UserId:    int(me.Id),
+			IsRunning: randall.OptionalBool(true),
+		})
+
+		if err != nil {
+			return err
+		}
+
+		if len(running) == 0 {
+			return fmt.Errorf("no timer is running")
+		}
+
+		timeEntryId = running[0].Id
+	}
+
+	var entry randall.TimeEntry
+	resp, err := a.client.TimeEntries.StopTimeEntry(timeEntryId)
+
+	if err := randall.DecodeResponse(resp, err, &entry); err != nil {
+		return err
+	}
+
+	return a.printTimeEntries([]randall.TimeEntry{entry})
+}
+
+func runLog(a *app, args []string) error {
+	flags := flag.NewFlagSet("log", flag.ExitOnError)
+	project := flags.String("project", "", "project id, code or name")
+	task := flags.String("task", "", "task id or name")
+	hours := flags.String("hours", "", "the duration, e.g. 1.5 or 1:30")
+	date := flags.String("date", "", "the spent date as YYYY-MM-DD, defaults to today")
+	notes := flags.String("notes", "", "notes of the time entry")
+	flags.Parse(args)
+
+	duration, err := parseHours(*hours)
+
+	if err != nil {
+		return err
+	}
+
+	spent, err := parseDate(*date)
+
+	if err != nil {
+		return err
+	}
+
+	projectId, taskId, err := a.resolveAssignment(*project, *task)
+
+	if err != nil {
+		return err
+	}
+
+	req := randall.CreateTimeEntryViaDurationRequest{
+		ProjectId: projectId,
+		TaskId:    taskId,
+		SpentDate: spent,
+		Hours:     randall.OptionalDecimal(duration),
+	}
+
+	if *notes != "" {
+		req.Notes = randall.OptionalString(*notes)
+	}
+
+	var entry randall.TimeEntry
+	resp, err := a.client.TimeEntries.CreateViaDuration(req)
+
+	if err := randall.DecodeResponse(resp, err, &entry); err != nil {
+		return err
+	}
+
+	return a.printTimeEntries([]randall.TimeEntry{entry})
+}
+
+func runToday(a *app, args []string) error {
+	flags := flag.NewFlagSet("today", flag.ExitOnError)
+	flags.Parse(args)
+
+	today := spentDate(time.Now())
+	return a.listTimeEntries(today, today)
+}
+
+func runWeek(a *app, args []string) error {
+	flags := flag.NewFlagSet("week", flag.ExitOnError)
+	flags.Parse(args)
+
+	today := spentDate(time.Now())
+	// Weeks start on Monday
+	monday := today.AddDate(0, 0, -((int(today.Weekday()) + 6) % 7))
+	return a.listTimeEntries(monday, monday.AddDate(0, 0, 6))
+}
+
+func (a *app) listTimeEntries(from, to time.Time) error {
+	me, err := a.me()
+
+	if err != nil {
+		return err
+	}
+
+	entries, err := a.client.TimeEntries.GetAllPages(randall.GetTimeEntriesParams{
+		UserId:   int(me.Id),
+		FromDate: randall.OptionalTime(from),
+		ToDate:   randall.OptionalTime(to),
+	})
+
+	if err != nil {
+		return err
+	}
+
+	return a.printTimeEntries(entries)
+}
+
+func (a *app) printTimeEntries(entries []randall.TimeEntry) error {
+	header := []string{"ID", "DATE", "PROJECT", "TASK", "HOURS", "NOTES"}
+	rows := make([][]string, 0, len(entries)+1)
+	total := decimal.Zero
+
+	for _, e := range entries {
+		hours := e.Hours.StringFixed(2)
+
+		if e.IsRunning {
+			hours += " (running)"
+		}
+
+		rows = append(rows, []string{
+			strconv.FormatUint(uint64(e.Id), 10),
+			e.SpentDate.Format("2006-01-02"),
+			e.Project.Name,
+			e.Task.Name,
+			hours,
+			firstLine(e.Notes),
+		})
+
+		total = total.Add(e.Hours)
+	}
+
+	if len(entries) > 1 {
+		rows = append(rows, []string{"", "", "", "Total", total.StringFixed(2), ""})
+	}
+
+	return a.print(entries, header, rows)
+}
+
+func (a *app) me() (randall.User, error) {
+	var user randall.User
+	resp, err := a.client.Users.MyUser()
+	return user, randall.DecodeResponse(resp, err, &user)
+}
+
+// Returns the local calendar date of t the way spent dates are sent to Harvest.
+func spentDate(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// Parses a YYYY-MM-DD date, defaulting to today if s is empty.
+func parseDate(s string) (time.Time, error) {
+	if s == "" {
+		return spentDate(time.Now()), nil
+	}
+
+	d, err := time.Parse("2006-01-02", s)
+
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q, expected YYYY-MM-DD", s)
+	}
+
+	return d, nil
+}
+
+// Parses a duration given as decimal hours, e.g. "1.5", or as hours and minutes, e.g. "1:30".
+func parseHours(s string) (decimal.Decimal, error) {
+	if s == "" {
+		return decimal.Zero, fmt.Errorf("-hours is required")
+	}
+
+	if h, m, ok := strings.Cut(s, ":"); ok {
+		hours, err1 := strconv.ParseUint(h, 10, 32)
+		minutes, err2 := strconv.ParseUint(m, 10, 32)
+
+		if err1 != nil || err2 != nil || minutes >= 60 {
+			return decimal.Zero, fmt.Errorf("invalid hours %q", s)
+		}
+
+		return decimal.NewFromInt(int64(hours*60 + minutes)).Div(decimal.NewFromInt(60)).Round(2), nil
+	}
+
+	hours, err := decimal.NewFromString(s)
+
+	if err != nil || !hours.IsPositive() {
+		return decimal.Zero, fmt.Errorf("invalid hours %q", s)
+	}
+
+	return hours, nil
+}
@@ -1,6 +1,9 @@
 package randall
 
 import (
+	"encoding/json"
+	"fmt"
+	"strings"
 	"time"
 )
 
@@ -24,6 +27,90 @@ type HarvestResponse struct {
 	StatusCode int
 	// The JSON payload of the response from Harvest.
 	Data map[string]interface{}
+
+	body []byte
+}
+
+// The error returned by randall's higher level operations when Harvest responds
+// with a non-2xx status code.
+type HarvestError struct {
+	// The HTTP status code of the response from Harvest.
+	StatusCode int
+	// The error message sent by Harvest, if any.
+	Message string
+}
+
+// A calendar date without a time component, sent by Harvest as YYYY-MM-DD.
+type HarvestDate struct {
+	time.Time
+}
+
+// A minimal representation of a related object embedded in a Harvest payload.
+type ObjectRef struct {
+	Id   uint   `json:"id"`
+	Name string `json:"name"`
+}
+
+func (e *HarvestError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("harvest responded with status code %d", e.StatusCode)
+	}
+
+	return fmt.Sprintf("harvest responded with status code %d: %s", e.StatusCode, e.Message)
+}
+
+// Returns true if the response has a 2xx status code.
+func (resp HarvestResponse) IsSuccess() bool {
+	return resp.StatusCode >= 200 && resp.StatusCode < 300
+}
+
+// Returns a *HarvestError if the response does not have a 2xx status code, otherwise nil.
+func (resp HarvestResponse) Err() error {
+	return checkResponse(resp)
+}
+
+// Decodes the JSON payload of the response into v. Decimal values are decoded from the
+// raw payload, so no precision is lost.
+func (resp HarvestResponse) Unmarshal(v interface{}) error {
+	body := resp.body
+
+	if body == nil {
+		b, err := json.Marshal(resp.Data)
+
+		if err != nil {
+			return err
+		}
+
+		body = b
+	}
+
+	return json.Unmarshal(body, v)
+}
+
+func (d HarvestDate) MarshalJSON() ([]byte, error) {
+	if d.IsZero() {
+		return []byte("null"), nil
+	}
+
+	return json.Marshal(d.Format("2006-01-02"))
+}
+
+func (d *HarvestDate) UnmarshalJSON(b []byte) error {
+	s := strings.Trim(string(b), `"`)
+
+	if s == "" || s == "null" {
+		d.Time = time.Time{}
+		return nil
+	}
+
+	t, err := time.Parse("2006-01-02", s)
+
+	if err != nil {
+		return err
+	}
+
+	d.Time = t
+	return nil
 }
 
 type MessageRecipient struct {
@@ -52,3 +139,126 @@ func getOptionalCollectionParams(params []HarvestCollectionParams) *HarvestColle
 
 	return nil
 }
+
+// Converts the collection params into the equivalent params for /time_entries.
+func (p HarvestCollectionParams) timeEntriesParams() GetTimeEntriesParams {
+	params := GetTimeEntriesParams{
+		IsBilled: p.IsBilled,
+		Page:     p.Page,
+		PerPage:  p.PerPage,
+	}
+
+	if p.UserId != nil {
+		params.UserId = int(*p.UserId)
+	}
+
+	if p.ClientId != nil {
+		params.ClientId = int(*p.ClientId)
+	}
+
+	if p.ProjectId != nil {
+		params.ProjectId = int(*p.ProjectId)
+	}
+
+	if !p.UpdatedSince.IsZero() {
+		params.UpdateSince = OptionalTime(p.UpdatedSince)
+	}
+
+	if !p.From.IsZero() {
+		params.FromDate = OptionalTime(p.From)
+	}
+
+	if !p.To.IsZero() {
+		params.ToDate = OptionalTime(p.To)
+	}
+
+	return params
+}
+
+// Returns a *HarvestError if the response does not have a 2xx status code.
+func checkResponse(resp HarvestResponse) error {
+	if resp.IsSuccess() {
+		return nil
+	}
+
+	harvestErr := &HarvestError{StatusCode: resp.StatusCode}
+
+	for _, key := range []string{"message", "error_description", "error"} {
+		if msg, ok := resp.Data[key].(string); ok && msg != "" {
+			harvestErr.Message = msg
+			break
+		}
+	}
+
+	return harvestErr
+}
+
+// Decodes the payload of a response into v, the way the typed helpers do. Returns err if
+// the request failed, or a *HarvestError if the response does not have a 2xx status code.
+func DecodeResponse(resp HarvestResponse, err error, v interface{}) error {
+	if err != nil {
+		return err
+	}
+
+	if err := checkResponse(resp); err != nil {
+		return err
+	}
+
+	return resp.Unmarshal(v)
+}
+
+// Calls fetch with the first of params, if any, for every page of a collection endpoint and
+// returns the decoded objects found under key across all pages.
+func getAllCollectionPages[T any](key string, params []HarvestCollectionParams, fetch func(params ...HarvestCollectionParams) (HarvestResponse, error)) ([]T, error) {
+	var param HarvestCollectionParams
+
+	if len(params) > 0 {
+		param = params[0]
+	}
+
+	return getAllPages[T](key, func(page int) (HarvestResponse, error) {
+		param.Page = OptionalInt(page)
+		return fetch(param)
+	})
+}
+
+// Calls fetch for every page of a collection endpoint, starting at page 1, and returns
+// the decoded objects found under key across all pages.
+func getAllPages[T any](key string, fetch func(page int) (HarvestResponse, error)) ([]T, error) {
+	var all []T
+	page := 1
+
+	for {
+		var payload map[string]json.RawMessage
+
+		resp, err := fetch(page)
+
+		if err := DecodeResponse(resp, err, &payload); err != nil {
+			return nil, err
+		}
+
+		var items []T
+
+		if raw, ok := payload[key]; ok {
+			if err := json.Unmarshal(raw, &items); err != nil {
+				return nil, err
+			}
+		}
+
+		all = append(all, items...)
+
+		var next *int
+
+		if raw, ok := payload["next_page"]; ok {
+			if err := json.Unmarshal(raw, &next); err != nil {
+				return nil, err
+			}
+		}
+
+		if next == nil || *next <= page {
+			return all, nil
+		}
+
+		page = *next
+	}
+}
@@ -2,328 +2,328 @@ package randall
 
 const (
 	// Timezones supported by Harvest API
-	AmericanSamoa             = "American Samoa"
-	InternationalDateLineWest = "International Date Line West"
-	MidwayIsland              = "Midway Island"
-	Hawaii                    = "Hawaii"
-	Alaska                    = "Alaska"
-	PacificTimeUsCanada       = "Pacific Time (US & Canada)"
-	Tijuana                   = "Tijuana"
-	Arizona                   = "Arizona"
-	Chihuahua                 = "Chihuahua"
-	Mazatlan                  = "Mazatlan"
-	MountainTimeUsCanada      = "Mountain Time (US & Canada)"
-	CentralAmerica            = "Central America"
-	CentralTimeUsCanada       = "Central Time (US & Canada)"
-	Guadalajara               = "Guadalajara"
-	MexicoCity                = "Mexico City"
-	Monterrey                 = "Monterrey"
-	Saskatchewan              = "Saskatchewan"
-	Bogota                    = "Bogota"
-	EasternTimeUsCanada       = "Eastern Time (US & Canada)"
-	IndianaEast               = "Indiana (East)"
-	Lima                      = "Lima"
-	Quito                     = "Quito"
-	AtlanticTimeCanada        = "Atlantic Time (Canada)"
-	Caracas                   = "Caracas"
-	Georgetown                = "Georgetown"
-	LaPaz                     = "La Paz"
-	PuertoRico                = "Puerto Rico"
-	Santiago                  = "Santiago"
-	Newfoundland              = "Newfoundland"
-	Brasilia                  = "Brasilia"
-	BuenosAires               = "Buenos Aires"
-	Greenland                 = "Greenland"
-	Montevideo                = "Montevideo"
-	MidAtlantic               = "Mid-Atlantic"
-	Azores                    = "Azores"
-	CapeVerdeIs               = "Cape Verde Is."
-	Casablanca                = "Casablanca"
-	Dublin                    = "Dublin"
-	Edinburgh                 = "Edinburgh"
-	Lisbon                    = "Lisbon"
-	London                    = "London"
-	Monrovia                  = "Monrovia"
-	Utc                       = "UTC"
-	Amsterdam                 = "Amsterdam"
-	Belgrade                  = "Belgrade"
-	Berlin                    = "Berlin"
-	Bern                      = "Bern"
-	Bratislava                = "Bratislava"
-	Brussels                  = "Brussels"
-	Budapest                  = "Budapest"
-	Copenhagen                = "Copenhagen"
-	Ljubljana                 = "Ljubljana"
-	Madrid                    = "Madrid"
-	Paris                     = "Paris"
-	Prague                    = "Prague"
-	Rome                      = "Rome"
-	Sarajevo                  = "Sarajevo"
-	Skopje                    = "Skopje"
-	Stockholm                 = "Stockholm"
-	Vienna                    = "Vienna"
-	Warsaw                    = "Warsaw"
-	WestCentralAfrica         = "West Central Africa"
-	Zagreb                    = "Zagreb"
-	Zurich                    = "Zurich"
-	Athens                    = "Athens"
-	Bucharest                 = "Bucharest"
-	Cairo                     = "Cairo"
-	Harare                    = "Harare"
-	Helsinki                  = "Helsinki"
-	Jerusalem                 = "Jerusalem"
-	Kaliningrad               = "Kaliningrad"
-	Kyiv                      = "Kyiv"
-	Pretoria                  = "Pretoria"
-	Riga                      = "Riga"
-	Sofia                     = "Sofia"
-	Tallinn                   = "Tallinn"
-	Vilnius                   = "Vilnius"
-	Baghdad                   = "Baghdad"
-	Istanbul                  = "Istanbul"
-	Kuwait                    = "Kuwait"
-	Minsk                     = "Minsk"
-	Moscow                    = "Moscow"
-	Nairobi                   = "Nairobi"
-	Riyadh                    = "Riyadh"
-	StPetersburg              = "St. Petersburg"
-	Volgograd                 = "Volgograd"
-	Tehran                    = "Tehran"
-	AbuDhabi                  = "Abu Dhabi"
-	Baku                      = "Baku"
-	Muscat                    = "Muscat"
-	Samara                    = "Samara"
-	Tbilisi                   = "Tbilisi"
-	Yerevan                   = "Yerevan"
-	Kabul                     = "Kabul"
-	Ekaterinburg              = "Ekaterinburg"
-	Islamabad                 = "Islamabad"
-	Karachi                   = "Karachi"
-	Tashkent                  = "Tashkent"
-	Chennai                   = "Chennai"
-	Kolkata                   = "Kolkata"
-	Mumbai                    = "Mumbai"
-	NewDelhi                  = "New Delhi"
-	SriJayawardenepura        = "Sri Jayawardenepura"
-	Kathmandu                 = "Kathmandu"
-	Almaty                    = "Almaty"
-	Astana                    = "Astana"
-	Dhaka                     = "Dhaka"
-	Urumqi                    = "Urumqi"
-	Rangoon                   = "Rangoon"
-	Bangkok                   = "Bangkok"
-	Hanoi                     = "Hanoi"
-	Jakarta                   = "Jakarta"
-	Krasnoyarsk               = "Krasnoyarsk"
-	Novosibirsk               = "Novosibirsk"
-	Beijing                   = "Beijing"
-	Chongqing                 = "Chongqing"
-	HongKong                  = "Hong Kong"
-	Irkutsk                   = "Irkutsk"
-	KualaLumpur               = "Kuala Lumpur"
-	Perth                     = "Perth"
-	Singapore                 = "Singapore"
-	Taipei                    = "Taipei"
-	Ulaanbaatar               = "Ulaanbaatar"
-	Osaka                     = "Osaka"
-	Sapporo                   = "Sapporo"
-	Seoul                     = "Seoul"
-	Tokyo                     = "Tokyo"
-	Yakutsk                   = "Yakutsk"
-	Adelaide                  = "Adelaide"
-	Darwin                    = "Darwin"
-	Brisbane                  = "Brisbane"
-	Canberra                  = "Canberra"
-	Guam                      = "Guam"
-	Hobart                    = "Hobart"
-	Melbourne                 = "Melbourne"
-	PortMoresby               = "Port Moresby"
-	Sydney                    = "Sydney"
-	Vladivostok               = "Vladivostok"
-	Magadan                   = "Magadan"
-	NewCaledonia              = "New Caledonia"
-	SolomonIs                 = "Solomon Is."
-	Srednekolymsk             = "Srednekolymsk"
-	Auckland                  = "Auckland"
-	Fiji                      = "Fiji"
-	Kamchatka                 = "Kamchatka"
-	MarshallIs                = "Marshall Is."
-	Wellington                = "Wellington"
-	ChathamIs                 = "Chatham Is."
-	NukuAlofa                 = "Nuku’alofa"
-	Samoa                     = "Samoa"
-	TokelauIs                 = "Tokelau Is."
+	AmericanSamoa             Timezone = "American Samoa"
+	InternationalDateLineWest Timezone = "International Date Line West"
+	MidwayIsland              Timezone = "Midway Island"
+	Hawaii                    Timezone = "Hawaii"
+	Alaska                    Timezone = "Alaska"
+	PacificTimeUsCanada       Timezone = "Pacific Time (US & Canada)"
+	Tijuana                   Timezone = "Tijuana"
+	Arizona                   Timezone = "Arizona"
+	Chihuahua                 Timezone = "Chihuahua"
+	Mazatlan                  Timezone = "Mazatlan"
+	MountainTimeUsCanada      Timezone = "Mountain Time (US & Canada)"
+	CentralAmerica            Timezone = "Central America"
+	CentralTimeUsCanada       Timezone = "Central Time (US & Canada)"
+	Guadalajara               Timezone = "Guadalajara"
+	MexicoCity                Timezone = "Mexico City"
+	Monterrey                 Timezone = "Monterrey"
+	Saskatchewan              Timezone = "Saskatchewan"
+	Bogota                    Timezone = "Bogota"
+	EasternTimeUsCanada       Timezone = "Eastern Time (US & Canada)"
+	IndianaEast               Timezone = "Indiana (East)"
+	Lima                      Timezone = "Lima"
+	Quito                     Timezone = "Quito"
+	AtlanticTimeCanada        Timezone = "Atlantic Time (Canada)"
+	Caracas                   Timezone = "Caracas"
+	Georgetown                Timezone = "Georgetown"
+	LaPaz                     Timezone = "La Paz"
+	PuertoRico                Timezone = "Puerto Rico"
+	Santiago                  Timezone = "Santiago"
+	Newfoundland              Timezone = "Newfoundland"
+	Brasilia                  Timezone = "Brasilia"
+	BuenosAires               Timezone = "Buenos Aires"
+	Greenland                 Timezone = "Greenland"
+	Montevideo                Timezone = "Montevideo"
+	MidAtlantic               Timezone = "Mid-Atlantic"
+	Azores                    Timezone = "Azores"
+	CapeVerdeIs               Timezone = "Cape Verde Is."
+	Casablanca                Timezone = "Casablanca"
+	Dublin                    Timezone = "Dublin"
+	Edinburgh                 Timezone = "Edinburgh"
+	Lisbon                    Timezone = "Lisbon"
+	London                    Timezone = "London"
+	Monrovia                  Timezone = "Monrovia"
+	Utc                       Timezone = "UTC"
+	Amsterdam                 Timezone = "Amsterdam"
+	Belgrade                  Timezone = "Belgrade"
+	Berlin                    Timezone = "Berlin"
+	Bern                      Timezone = "Bern"
+	Bratislava                Timezone = "Bratislava"
+	Brussels                  Timezone = "Brussels"
+	Budapest                  Timezone = "Budapest"
+	Copenhagen                Timezone = "Copenhagen"
+	Ljubljana                 Timezone = "Ljubljana"
+	Madrid                    Timezone = "Madrid"
+	Paris                     Timezone = "Paris"
+	Prague                    Timezone = "Prague"
+	Rome                      Timezone = "Rome"
+	Sarajevo                  Timezone = "Sarajevo"
+	Skopje                    Timezone = "Skopje"
+	Stockholm                 Timezone = "Stockholm"
+	Vienna                    Timezone = "Vienna"
+	Warsaw                    Timezone = "Warsaw"
+	WestCentralAfrica         Timezone = "West Central Africa"
+	Zagreb                    Timezone = "Zagreb"
+	Zurich                    Timezone = "Zurich"
+	Athens                    Timezone = "Athens"
+	Bucharest                 Timezone = "Bucharest"
+	Cairo                     Timezone = "Cairo"
+	Harare                    Timezone = "Harare"
+	Helsinki                  Timezone = "Helsinki"
+	Jerusalem                 Timezone = "Jerusalem"
+	Kaliningrad               Timezone = "Kaliningrad"
+	Kyiv                      Timezone = "Kyiv"
+	Pretoria                  Timezone = "Pretoria"
+	Riga                      Timezone = "Riga"
+	Sofia                     Timezone = "Sofia"
+	Tallinn                   Timezone = "Tallinn"
+	Vilnius                   Timezone = "Vilnius"
+	Baghdad                   Timezone = "Baghdad"
+	Istanbul                  Timezone = "Istanbul"
+	Kuwait                    Timezone = "Kuwait"
+	Minsk                     Timezone = "Minsk"
+	Moscow                    Timezone = "Moscow"
+	Nairobi                   Timezone = "Nairobi"
+	Riyadh                    Timezone = "Riyadh"
+	StPetersburg              Timezone = "St. Petersburg"
+	Volgograd                 Timezone = "Volgograd"
+	Tehran                    Timezone = "Tehran"
+	AbuDhabi                  Timezone = "Abu Dhabi"
+	Baku                      Timezone = "Baku"
+	Muscat                    Timezone = "Muscat"
+	Samara                    Timezone = "Samara"
+	Tbilisi                   Timezone = "Tbilisi"
+	Yerevan                   Timezone = "Yerevan"
+	Kabul                     Timezone = "Kabul"
+	Ekaterinburg              Timezone = "Ekaterinburg"
+	Islamabad                 Timezone = "Islamabad"
+	Karachi                   Timezone = "Karachi"
+	Tashkent                  Timezone = "Tashkent"
+	Chennai                   Timezone = "Chennai"
+	Kolkata                   Timezone = "Kolkata"
+	Mumbai                    Timezone = "Mumbai"
+	NewDelhi                  Timezone = "New Delhi"
+	SriJayawardenepura        Timezone = "Sri Jayawardenepura"
+	Kathmandu                 Timezone = "Kathmandu"
+	Almaty                    Timezone = "Almaty"
+	Astana                    Timezone = "Astana"
+	Dhaka                     Timezone = "Dhaka"
+	Urumqi                    Timezone = "Urumqi"
+	Rangoon                   Timezone = "Rangoon"
+	Bangkok                   Timezone = "Bangkok"
+	Hanoi                     Timezone = "Hanoi"
+	Jakarta                   Timezone = "Jakarta"
+	Krasnoyarsk               Timezone = "Krasnoyarsk"
+	Novosibirsk               Timezone = "Novosibirsk"
+	Beijing                   Timezone = "Beijing"
+	Chongqing                 Timezone = "Chongqing"
+	HongKong                  Timezone = "Hong Kong"
+	Irkutsk                   Timezone = "Irkutsk"
+	KualaLumpur               Timezone = "Kuala Lumpur"
+	Perth                     Timezone = "Perth"
+	Singapore                 Timezone = "Singapore"
+	Taipei                    Timezone = "Taipei"
+	Ulaanbaatar               Timezone = "Ulaanbaatar"
+	Osaka                     Timezone = "Osaka"
+	Sapporo                   Timezone = "Sapporo"
+	Seoul                     Timezone = "Seoul"
+	Tokyo                     Timezone = "Tokyo"
+	Yakutsk                   Timezone = "Yakutsk"
+	Adelaide                  Timezone = "Adelaide"
+	Darwin                    Timezone = "Darwin"
+	Brisbane                  Timezone = "Brisbane"
+	Canberra                  Timezone = "Canberra"
+	Guam                      Timezone = "Guam"
+	Hobart                    Timezone = "Hobart"
+	Melbourne                 Timezone = "Melbourne"
+	PortMoresby               Timezone = "Port Moresby"
+	Sydney                    Timezone = "Sydney"
+	Vladivostok               Timezone = "Vladivostok"
+	Magadan                   Timezone = "Magadan"
+	NewCaledonia              Timezone = "New Caledonia"
+	SolomonIs                 Timezone = "Solomon Is."
+	Srednekolymsk             Timezone = "Srednekolymsk"
+	Auckland                  Timezone = "Auckland"
+	Fiji                      Timezone = "Fiji"
+	Kamchatka                 Timezone = "Kamchatka"
+	MarshallIs                Timezone = "Marshall Is."
+	Wellington                Timezone = "Wellington"
+	ChathamIs                 Timezone = "Chatham Is."
+	NukuAlofa                 Timezone = "Nuku’alofa"
+	Samoa                     Timezone = "Samoa"
+	TokelauIs                 Timezone = "Tokelau Is."
 
 	// Currencies supported by Harvest API
-	UnitedStatesDollar                  = "USD"
-	Euro                                = "EUR"
-	BritishPound                        = "GBP"
-	AustralianDollar                    = "AUD"
-	CanadianDollar                      = "CAD"
-	JapaneseYen                         = "JPY"
-	UnitedArabEmiratesDirham            = "AED"
-	AfghanAfghani                       = "AFN"
-	AlbanianLek                         = "ALL"
-	ArmenianDram                        = "AMD"
-	NetherlandsAntilleanGulden          = "ANG"
-	AngolanKwanza                       = "AOA"
-	ArgentinePeso                       = "ARS"
-	ArubanFlorin                        = "AWG"
-	AzerbaijaniManat                    = "AZN"
-	BosniaandHerzegovinaConvertibleMark = "BAM"
-	BarbadianDollar                     = "BBD"
-	BangladeshiTaka                     = "BDT"
-	BulgarianLev                        = "BGN"
-	BahrainiDinar                       = "BHD"
-	BurundianFranc                      = "BIF"
-	BermudianDollar                     = "BMD"
-	BruneiDollar                        = "BND"
-	BolivianBoliviano                   = "BOB"
-	BrazilianReal                       = "BRL"
-	BahamianDollar                      = "BSD"
-	BhutaneseNgultrum                   = "BTN"
-	BotswanaPula                        = "BWP"
-	BelarusianRubleN                    = "BYN"
-	BelarusianRubleR                    = "BYR"
-	BelizeDollar                        = "BZD"
-	CongoleseFranc                      = "CDF"
-	SwissFranc                          = "CHF"
-	UnidaddeFomento                     = "CLF"
-	ChileanPeso                         = "CLP"
-	ChineseRenminbiYuan                 = "CNY"
-	ColombianPeso                       = "COP"
-	CostaRicanColón                     = "CRC"
-	CubanConvertiblePeso                = "CUC"
-	CubanPeso                           = "CUP"
-	CapeVerdeanEscudo                   = "CVE"
-	CzechKoruna                         = "CZK"
-	DjiboutianFranc                     = "DJF"
-	DanishKrone                         = "DKK"
-	DominicanPeso                       = "DOP"
-	AlgerianDinar                       = "DZD"
-	EgyptianPound                       = "EGP"
-	EritreanNakfa                       = "ERN"
-	EthiopianBirr                       = "ETB"
-	FijianDollar                        = "FJD"
-	FalklandPound                       = "FKP"
-	GeorgianLari                        = "GEL"
-	GhanaianCedi                        = "GHS"
-	GibraltarPound                      = "GIP"
-	GambianDalasi                       = "GMD"
-	GuineanFranc                        = "GNF"
-	GuatemalanQuetzal                   = "GTQ"
-	GuyaneseDollar                      = "GYD"
-	HongKongDollar                      = "HKD"
-	HonduranLempira                     = "HNL"
-	CroatianKuna                        = "HRK"
-	HaitianGourde                       = "HTG"
-	HungarianForint                     = "HUF"
-	IndonesianRupiah                    = "IDR"
-	IsraeliNewSheqel                    = "ILS"
-	IndianRupee                         = "INR"
-	IraqiDinar                          = "IQD"
-	IranianRial                         = "IRR"
-	IcelandicKróna                      = "ISK"
-	JamaicanDollar                      = "JMD"
-	JordanianDinar                      = "JOD"
-	KenyanShilling                      = "KES"
-	KyrgyzstaniSom                      = "KGS"
-	CambodianRiel                       = "KHR"
-	ComorianFranc                       = "KMF"
-	NorthKoreanWon                      = "KPW"
-	SouthKoreanWon                      = "KRW"
-	KuwaitiDinar                        = "KWD"
-	CaymanIslandsDollar                 = "KYD"
-	KazakhstaniTenge                    = "KZT"
-	LaoKip                              = "LAK"
-	LebanesePound                       = "LBP"
-	SriLankanRupee                      = "LKR"
-	LiberianDollar                      = "LRD"
-	LesothoLoti                         = "LSL"
-	LithuanianLitas                     = "LTL"
-	LatvianLats                         = "LVL"
-	LibyanDinar                         = "LYD"
-	MoroccanDirham                      = "MAD"
-	MoldovanLeu                         = "MDL"
-	MalagasyAriary                      = "MGA"
-	MacedonianDenar                     = "MKD"
-	MyanmarKyat                         = "MMK"
-	MongolianTögrög                     = "MNT"
-	MacanesePataca                      = "MOP"
-	MauritanianOuguiya                  = "MRO"
-	MauritianRupee                      = "MUR"
-	MaldivianRufiyaa                    = "MVR"
-	MalawianKwacha                      = "MWK"
-	MexicanPeso                         = "MXN"
-	MalaysianRinggit                    = "MYR"
-	MozambicanMetical                   = "MZN"
-	NamibianDollar                      = "NAD"
-	NigerianNaira                       = "NGN"
-	NicaraguanCórdoba                   = "NIO"
-	NorwegianKrone                      = "NOK"
-	NepaleseRupee                       = "NPR"
-	NewZealandDollar                    = "NZD"
-	OmaniRial                           = "OMR"
-	PanamanianBalboa                    = "PAB"
-	PeruvianSol                         = "PEN"
-	PapuaNewGuineanKina                 = "PGK"
-	PhilippinePeso                      = "PHP"
-	PakistaniRupee                      = "PKR"
-	PolishZłoty                         = "PLN"
-	ParaguayanGuaraní                   = "PYG"
-	QatariRiyal                         = "QAR"
-	RomanianLeu                         = "RON"
-	SerbianDinar                        = "RSD"
-	RussianRuble                        = "RUB"
-	RwandanFranc                        = "RWF"
-	SaudiRiyal                          = "SAR"
-	SolomonIslandsDollar                = "SBD"
-	SeychelloisRupee                    = "SCR"
-	SudanesePound                       = "SDG"
-	SwedishKrona                        = "SEK"
-	SingaporeDollar                     = "SGD"
-	SaintHelenianPound                  = "SHP"
-	SlovakKoruna                        = "SKK"
-	SierraLeoneanLeone                  = "SLL"
-	SomaliShilling                      = "SOS"
-	SurinameseDollar                    = "SRD"
-	SouthSudanesePound                  = "SSP"
-	SaoTomeAndPrincipeDobra             = "STD"
-	SalvadoranColon                     = "SVC"
-	SyrianPound                         = "SYP"
-	SwaziLilangeni                      = "SZL"
-	ThaiBaht                            = "THB"
-	TajikistaniSomoni                   = "TJS"
-	TurkmenistaniManat                  = "TMT"
-	TunisianDinar                       = "TND"
-	TonganPaAnga                        = "TOP"
-	TurkishLira                         = "TRY"
-	TrinidadandTobagoDollar             = "TTD"
-	NewTaiwanDollar                     = "TWD"
-	TanzanianShilling                   = "TZS"
-	UkrainianHryvnia                    = "UAH"
-	UgandanShilling                     = "UGX"
-	UruguayanPeso                       = "UYU"
-	UzbekistanSom                       = "UZS"
-	VenezuelanBolivar                   = "VEF"
-	VietnameseDong                      = "VND"
-	VanuatuVatu                         = "VUV"
-	SamoanTala                          = "WST"
-	CentralAfricanCfaFranc              = "XAF"
-	SilverTroyOunce                     = "XAG"
-	GoldTroyOunce                       = "XAU"
-	EuropeanCompositeUnit               = "XBA"
-	EuropeanMonetaryUnit                = "XBB"
-	EuropeanUnitOfAccount9              = "XBC"
-	EuropeanUnitOfAccount17             = "XBD"
-	EastCaribbeanDollar                 = "XCD"
-	SpecialDrawingRights                = "XDR"
-	WestAfricanCfaFranc                 = "XOF"
-	Palladium                           = "XPD"
-	CfpFranc                            = "XPF"
-	Platinum                            = "XPT"
-	YemeniRial                          = "YER"
-	SouthAfricanRand                    = "ZAR"
-	ZambianKwachaK                      = "ZMK"
-	ZambianKwachaW                      = "ZMW"
+	UnitedStatesDollar                  Currency = "USD"
+	Euro                                Currency = "EUR"
+	BritishPound                        Currency = "GBP"
+	AustralianDollar                    Currency = "AUD"
+	CanadianDollar                      Currency = "CAD"
+	JapaneseYen                         Currency = "JPY"
+	UnitedArabEmiratesDirham            Currency = "AED"
+	AfghanAfghani                       Currency = "AFN"
+	AlbanianLek                         Currency = "ALL"
+	ArmenianDram                        Currency = "AMD"
+	NetherlandsAntilleanGulden          Currency = "ANG"
+	AngolanKwanza                       Currency = "AOA"
+	ArgentinePeso                       Currency = "ARS"
+	ArubanFlorin                        Currency = "AWG"
+	AzerbaijaniManat                    Currency = "AZN"
+	BosniaandHerzegovinaConvertibleMark Currency = "BAM"
+	BarbadianDollar                     Currency = "BBD"
+	BangladeshiTaka                     Currency = "BDT"
+	BulgarianLev                        Currency = "BGN"
+	BahrainiDinar                       Currency = "BHD"
+	BurundianFranc                      Currency = "BIF"
+	BermudianDollar                     Currency = "BMD"
+	BruneiDollar                        Currency = "BND"
+	BolivianBoliviano                   Currency = "BOB"
+	BrazilianReal                       Currency = "BRL"
+	BahamianDollar                      Currency = "BSD"
+	BhutaneseNgultrum                   Currency = "BTN"
+	BotswanaPula                        Currency = "BWP"
+	BelarusianRubleN                    Currency = "BYN"
+	BelarusianRubleR                    Currency = "BYR"
+	BelizeDollar                        Currency = "BZD"
+	CongoleseFranc                      Currency = "CDF"
+	SwissFranc                          Currency = "CHF"
+	UnidaddeFomento                     Currency = "CLF"
+	ChileanPeso                         Currency = "CLP"
+	ChineseRenminbiYuan                 Currency = "CNY"
+	ColombianPeso                       Currency = "COP"
+	CostaRicanColón                     Currency = "CRC"
+	CubanConvertiblePeso                Currency = "CUC"
+	CubanPeso                           Currency = "CUP"
+	CapeVerdeanEscudo                   Currency = "CVE"
+	CzechKoruna                         Currency = "CZK"
+	DjiboutianFranc                     Currency = "DJF"
+	DanishKrone                         Currency = "DKK"
+	DominicanPeso                       Currency = "DOP"
+	AlgerianDinar                       Currency = "DZD"
+	EgyptianPound                       Currency = "EGP"
+	EritreanNakfa                       Currency = "ERN"
+	EthiopianBirr                       Currency = "ETB"
+	FijianDollar                        Currency = "FJD"
+	FalklandPound                       Currency = "FKP"
+	GeorgianLari                        Currency = "GEL"
+	GhanaianCedi                        Currency = "GHS"
+	GibraltarPound                      Currency = "GIP"
+	GambianDalasi                       Currency = "GMD"
+	GuineanFranc                        Currency = "GNF"
+	GuatemalanQuetzal                   Currency = "GTQ"
+	GuyaneseDollar                      Currency = "GYD"
+	HongKongDollar                      Currency = "HKD"
+	HonduranLempira                     Currency = "HNL"
+	CroatianKuna                        Currency = "HRK"
+	HaitianGourde                       Currency = "HTG"
+	HungarianForint                     Currency = "HUF"
+	IndonesianRupiah                    Currency = "IDR"
+	IsraeliNewSheqel                    Currency = "ILS"
+	IndianRupee                         Currency = "INR"
+	IraqiDinar                          Currency = "IQD"
+	IranianRial                         Currency = "IRR"
+	IcelandicKróna                      Currency = "ISK"
+	JamaicanDollar                      Currency = "JMD"
+	JordanianDinar                      Currency = "JOD"
+	KenyanShilling                      Currency = "KES"
+	KyrgyzstaniSom                      Currency = "KGS"
+	CambodianRiel                       Currency = "KHR"
+	ComorianFranc                       Currency = "KMF"
+	NorthKoreanWon                      Currency = "KPW"
+	SouthKoreanWon                      Currency = "KRW"
+	KuwaitiDinar                        Currency = "KWD"
+	CaymanIslandsDollar                 Currency = "KYD"
+	KazakhstaniTenge                    Currency = "KZT"
+	LaoKip                              Currency = "LAK"
+	LebanesePound                       Currency = "LBP"
+	SriLankanRupee                      Currency = "LKR"
+	LiberianDollar                      Currency = "LRD"
+	LesothoLoti                         Currency = "LSL"
+	LithuanianLitas                     Currency = "LTL"
+	LatvianLats                         Currency = "LVL"
+	LibyanDinar                         Currency = "LYD"
+	MoroccanDirham                      Currency = "MAD"
+	MoldovanLeu                         Currency = "MDL"
+	MalagasyAriary                      Currency = "MGA"
+	MacedonianDenar                     Currency = "MKD"
+	MyanmarKyat                         Currency = "MMK"
+	MongolianTögrög                     Currency = "MNT"
+	MacanesePataca                      Currency = "MOP"
+	MauritanianOuguiya                  Currency = "MRO"
+	MauritianRupee                      Currency = "MUR"
+	MaldivianRufiyaa                    Currency = "MVR"
+	MalawianKwacha                      Currency = "MWK"
+	MexicanPeso                         Currency = "MXN"
+	MalaysianRinggit                    Currency = "MYR"
+	MozambicanMetical                   Currency = "MZN"
+	NamibianDollar                      Currency = "NAD"
+	NigerianNaira                       Currency = "NGN"
+	NicaraguanCórdoba                   Currency = "NIO"
+	NorwegianKrone                      Currency = "NOK"
+	NepaleseRupee                       Currency = "NPR"
+	NewZealandDollar                    Currency = "NZD"
+	OmaniRial                           Currency = "OMR"
+	PanamanianBalboa                    Currency = "PAB"
+	PeruvianSol                         Currency = "PEN"
+	PapuaNewGuineanKina                 Currency = "PGK"
+	PhilippinePeso                      Currency = "PHP"
+	PakistaniRupee                      Currency = "PKR"
+	PolishZłoty                         Currency = "PLN"
+	ParaguayanGuaraní                   Currency = "PYG"
+	QatariRiyal                         Currency = "QAR"
+	RomanianLeu                         Currency = "RON"
+	SerbianDinar                        Currency = "RSD"
+	RussianRuble                        Currency = "RUB"
+	RwandanFranc                        Currency = "RWF"
+	SaudiRiyal                          Currency = "SAR"
+	SolomonIslandsDollar                Currency = "SBD"
+	SeychelloisRupee                    Currency = "SCR"
+	SudanesePound                       Currency = "SDG"
+	SwedishKrona                        Currency = "SEK"
+	SingaporeDollar                     Currency = "SGD"
+	SaintHelenianPound                  Currency = "SHP"
+	SlovakKoruna                        Currency = "SKK"
+	SierraLeoneanLeone                  Currency = "SLL"
+	SomaliShilling                      Currency = "SOS"
+	SurinameseDollar                    Currency = "SRD"
+	SouthSudanesePound                  Currency = "SSP"
+	SaoTomeAndPrincipeDobra             Currency = "STD"
+	SalvadoranColon                     Currency = "SVC"
+	SyrianPound                         Currency = "SYP"
+	SwaziLilangeni                      Currency = "SZL"
+	ThaiBaht                            Currency = "THB"
+	TajikistaniSomoni                   Currency = "TJS"
+	TurkmenistaniManat                  Currency = "TMT"
+	TunisianDinar                       Currency = "TND"
+	TonganPaAnga                        Currency = "TOP"
+	TurkishLira                         Currency = "TRY"
+	TrinidadandTobagoDollar             Currency = "TTD"
+	NewTaiwanDollar                     Currency = "TWD"
+	TanzanianShilling                   Currency = "TZS"
+	UkrainianHryvnia                    Currency = "UAH"
+	UgandanShilling                     Currency = "UGX"
+	UruguayanPeso                       Currency = "UYU"
+	UzbekistanSom                       Currency = "UZS"
+	VenezuelanBolivar                   Currency = "VEF"
+	VietnameseDong                      Currency = "VND"
+	VanuatuVatu                         Currency = "VUV"
+	SamoanTala                          Currency = "WST"
+	CentralAfricanCfaFranc              Currency = "XAF"
+	SilverTroyOunce                     Currency = "XAG"
+	GoldTroyOunce                       Currency = "XAU"
+	EuropeanCompositeUnit               Currency = "XBA"
+	EuropeanMonetaryUnit                Currency = "XBB"
+	EuropeanUnitOfAccount9              Currency = "XBC"
+	EuropeanUnitOfAccount17             Currency = "XBD"
+	EastCaribbeanDollar                 Currency = "XCD"
+	SpecialDrawingRights                Currency = "XDR"
+	WestAfricanCfaFranc                 Currency = "XOF"
+	Palladium                           Currency = "XPD"
+	CfpFranc                            Currency = "XPF"
+	Platinum                            Currency = "XPT"
+	YemeniRial                          Currency = "YER"
+	SouthAfricanRand                    Currency = "ZAR"
+	ZambianKwachaK                      Currency = "ZMK"
+	ZambianKwachaW                      Currency = "ZMW"
 )
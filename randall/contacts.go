@@ -1,6 +1,9 @@
 package randall
 
-import "fmt"
+import (
+	"fmt"
+	"time"
+)
 
 // Encapsulates the Harvest API methods under /contacts
 type ContactsApi struct {
@@ -30,6 +33,21 @@ type PatchContactRequest struct {
 	Fax         *string `json:"fax,omitempty"`
 }
 
+// A client contact as returned by the Harvest API.
+type Contact struct {
+	Id          uint      `json:"id"`
+	Client      ObjectRef `json:"client"`
+	Title       string    `json:"title"`
+	FirstName   string    `json:"first_name"`
+	LastName    string    `json:"last_name"`
+	Email       string    `json:"email"`
+	OfficePhone string    `json:"phone_office"`
+	MobilePhone string    `json:"phone_mobile"`
+	Fax         string    `json:"fax"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
 func newContactsV2(client *internalClient) ContactsApi {
 	return ContactsApi{
 		baseUrl: "v2/contacts",
@@ -41,6 +59,11 @@ func (api ContactsApi) GetAll(params ...HarvestCollectionParams) (HarvestRespons
 	return api.client.doGet(api.baseUrl, getOptionalCollectionParams(params))
 }
 
+// Retrieves every page of contacts matching params as typed Contact objects.
+func (api ContactsApi) GetAllPages(params ...HarvestCollectionParams) ([]Contact, error) {
+	return getAllCollectionPages[Contact]("contacts", params, api.GetAll)
+}
+
 func (api ContactsApi) Get(contactId uint) (HarvestResponse, error) {
 	return api.client.doGet(fmt.Sprintf("%s/%d", api.baseUrl, contactId))
 }
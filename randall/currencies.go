@@ -0,0 +1,239 @@
+package randall
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+// The ISO 4217 code of a currency supported by the Harvest API, e.g. UnitedStatesDollar.
+type Currency string
+
+// The number of decimal places of every currency supported by the Harvest API, per the
+// minor units of ISO 4217. Currencies without minor units, such as precious metals, have none.
+var currencyMinorUnits = map[Currency]int32{
+	UnitedStatesDollar:                  2,
+	Euro:                                2,
+	BritishPound:                        2,
+	AustralianDollar:                    2,
+	CanadianDollar:                      2,
+	JapaneseYen:                         0,
+	UnitedArabEmiratesDirham:            2,
+	AfghanAfghani:                       2,
+	AlbanianLek:                         2,
+	ArmenianDram:                        2,
+	NetherlandsAntilleanGulden:          2,
+	AngolanKwanza:                       2,
+	ArgentinePeso:                       2,
+	ArubanFlorin:                        2,
+	AzerbaijaniManat:                    2,
+	BosniaandHerzegovinaConvertibleMark: 2,
+	BarbadianDollar:                     2,
+	BangladeshiTaka:                     2,
+	BulgarianLev:                        2,
+	BahrainiDinar:                       3,
+	BurundianFranc:                      0,
+	BermudianDollar:                     2,
+	BruneiDollar:                        2,
+	BolivianBoliviano:                   2,
+	BrazilianReal:                       2,
+	BahamianDollar:                      2,
+	BhutaneseNgultrum:                   2,
+	BotswanaPula:                        2,
+	BelarusianRubleN:                    2,
+	BelarusianRubleR:                    0,
+	BelizeDollar:                        2,
+	CongoleseFranc:                      2,
+	SwissFranc:                          2,
+	UnidaddeFomento:                     4,
+	ChileanPeso:                         0,
+	ChineseRenminbiYuan:                 2,
+	ColombianPeso:                       2,
+	CostaRicanColón:                     2,
+	CubanConvertiblePeso:                2,
+	CubanPeso:                           2,
+	CapeVerdeanEscudo:                   2,
+	CzechKoruna:                         2,
+	DjiboutianFranc:                     0,
+	DanishKrone:                         2,
+	DominicanPeso:                       2,
+	AlgerianDinar:                       2,
+	EgyptianPound:                       2,
+	EritreanNakfa:                       2,
+	EthiopianBirr:                       2,
+	FijianDollar:                        2,
+	FalklandPound:                       2,
+	GeorgianLari:                        2,
+	GhanaianCedi:                        2,
+	GibraltarPound:                      2,
+	GambianDalasi:                       2,
+	GuineanFranc:                        0,
+	GuatemalanQuetzal:                   2,
+	GuyaneseDollar:                      2,
+	HongKongDollar:                      2,
+	HonduranLempira:                     2,
+	CroatianKuna:                        2,
+	HaitianGourde:                       2,
+	HungarianForint:                     2,
+	IndonesianRupiah:                    2,
+	IsraeliNewSheqel:                    2,
+	IndianRupee:                         2,
+	IraqiDinar:                          3,
+	IranianRial:                         2,
+	IcelandicKróna:                      0,
+	JamaicanDollar:                      2,
+	JordanianDinar:                      3,
+	KenyanShilling:                      2,
+	KyrgyzstaniSom:                      2,
+	CambodianRiel:                       2,
+	ComorianFranc:                       0,
+	NorthKoreanWon:                      2,
+	SouthKoreanWon:                      0,
+	KuwaitiDinar:                        3,
+	CaymanIslandsDollar:                 2,
+	KazakhstaniTenge:                    2,
+	LaoKip:                              2,
+	LebanesePound:                       2,
+	SriLankanRupee:                      2,
+	LiberianDollar:                      2,
+	LesothoLoti:                         2,
+	LithuanianLitas:                     2,
+	LatvianLats:                         2,
+	LibyanDinar:                         3,
+	MoroccanDirham:                      2,
+	MoldovanLeu:                         2,
+	MalagasyAriary:                      2,
+	MacedonianDenar:                     2,
+	MyanmarKyat:                         2,
+	MongolianTögrög:                     2,
+	MacanesePataca:                      2,
+	MauritanianOuguiya:                  2,
+	MauritianRupee:                      2,
+	MaldivianRufiyaa:                    2,
+	MalawianKwacha:                      2,
+	MexicanPeso:                         2,
+	MalaysianRinggit:                    2,
+	MozambicanMetical:                   2,
+	NamibianDollar:                      2,
+	NigerianNaira:                       2,
+	NicaraguanCórdoba:                   2,
+	NorwegianKrone:                      2,
+	NepaleseRupee:                       2,
+	NewZealandDollar:                    2,
+	OmaniRial:                           3,
+	PanamanianBalboa:                    2,
+	PeruvianSol:                         2,
+	PapuaNewGuineanKina:                 2,
+	PhilippinePeso:                      2,
+	PakistaniRupee:                      2,
+	PolishZłoty:                         2,
+	ParaguayanGuaraní:                   0,
+	QatariRiyal:                         2,
+	RomanianLeu:                         2,
+	SerbianDinar:                        2,
+	RussianRuble:                        2,
+	RwandanFranc:                        0,
+	SaudiRiyal:                          2,
+	SolomonIslandsDollar:                2,
+	SeychelloisRupee:                    2,
+	SudanesePound:                       2,
+	SwedishKrona:                        2,
+	SingaporeDollar:                     2,
+	SaintHelenianPound:                  2,
+	SlovakKoruna:                        2,
+	SierraLeoneanLeone:                  2,
+	SomaliShilling:                      2,
+	SurinameseDollar:                    2,
+	SouthSudanesePound:                  2,
+	SaoTomeAndPrincipeDobra:             2,
+	SalvadoranColon:                     2,
+	SyrianPound:                         2,
+	SwaziLilangeni:                      2,
+	ThaiBaht:                            2,
+	TajikistaniSomoni:                   2,
+	TurkmenistaniManat:                  2,
+	TunisianDinar:                       3,
+	TonganPaAnga:                        2,
+	TurkishLira:                         2,
+	TrinidadandTobagoDollar:             2,
+	NewTaiwanDollar:                     2,
+	TanzanianShilling:                   2,
+	UkrainianHryvnia:                    2,
+	UgandanShilling:                     0,
+	UruguayanPeso:                       2,
+	UzbekistanSom:                       2,
+	VenezuelanBolivar:                   2,
+	VietnameseDong:                      0,
+	VanuatuVatu:                         0,
+	SamoanTala:                          2,
+	CentralAfricanCfaFranc:              0,
+	SilverTroyOunce:                     0,
+	GoldTroyOunce:                       0,
+	EuropeanCompositeUnit:               0,
+	EuropeanMonetaryUnit:                0,
+	EuropeanUnitOfAccount9:              0,
+	EuropeanUnitOfAccount17:             0,
+	EastCaribbeanDollar:                 2,
+	SpecialDrawingRights:                0,
+	WestAfricanCfaFranc:                 0,
+	Palladium:                           0,
+	CfpFranc:                            0,
+	Platinum:                            0,
+	YemeniRial:                          2,
+	SouthAfricanRand:                    2,
+	ZambianKwachaK:                      2,
+	ZambianKwachaW:                      2,
+}
+
+// Parses an ISO 4217 currency code, ignoring case.
+func ParseCurrency(s string) (Currency, error) {
+	c := Currency(strings.ToUpper(strings.TrimSpace(s)))
+
+	if !c.Valid() {
+		return c, fmt.Errorf("invalid currency %q", s)
+	}
+
+	return c, nil
+}
+
+// Returns whether Harvest supports the currency.
+func (c Currency) Valid() bool {
+	_, ok := currencyMinorUnits[c]
+	return ok
+}
+
+func (c Currency) String() string {
+	return string(c)
+}
+
+func (c Currency) MarshalJSON() ([]byte, error) {
+	return marshalEnum(c)
+}
+
+func (c *Currency) UnmarshalJSON(b []byte) (err error) {
+	*c, err = unmarshalEnum(b, ParseCurrency)
+	return err
+}
+
+// Returns the number of decimal places amounts in the currency are expressed in.
+func CurrencyMinorUnits(currency Currency) (int32, error) {
+	units, ok := currencyMinorUnits[currency]
+
+	if !ok {
+		return 0, fmt.Errorf("unsupported currency %q", currency)
+	}
+
+	return units, nil
+}
+
+// Rounds amount to the minor units of the currency, half away from zero.
+func RoundToCurrency(amount decimal.Decimal, currency Currency) (decimal.Decimal, error) {
+	units, err := CurrencyMinorUnits(currency)
+
+	if err != nil {
+		return amount, err
+	}
+
+	return amount.Round(units), nil
+}
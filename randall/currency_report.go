@@ -0,0 +1,156 @@
+package randall
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Amounts in several currencies and their sum in a base currency.
+type CurrencyTotals struct {
+	// The sum of the amounts in every original currency.
+	ByCurrency map[Currency]Money
+	// The sum of the amounts in every original currency, converted into the base currency.
+	Converted map[Currency]Money
+	// The sum of every amount in the base currency, rounded to its minor units.
+	Total Money
+}
+
+// Invoiced, expense and billable time totals of a period in a base currency.
+type CurrencyReport struct {
+	BaseCurrency Currency
+	From         time.Time
+	To           time.Time
+	// The amounts of the invoices issued in the period. Drafts are ignored.
+	Invoiced CurrencyTotals
+	// The due amounts of the invoices issued in the period.
+	Outstanding CurrencyTotals
+	// The total cost of the expenses spent in the period.
+	Expenses CurrencyTotals
+	// The billable amount of the time tracked in the period.
+	BillableTime CurrencyTotals
+}
+
+// Builds reports converting amounts into a base currency.
+type CurrencyReporter struct {
+	client       *HarvestClient
+	rates        RateProvider
+	baseCurrency Currency
+}
+
+// Initializes a new CurrencyReporter converting amounts into baseCurrency with the rates
+// of rates.
+func NewCurrencyReporter(client *HarvestClient, rates RateProvider, baseCurrency Currency) *CurrencyReporter {
+	return &CurrencyReporter{
+		client:       client,
+		rates:        rates,
+		baseCurrency: baseCurrency,
+	}
+}
+
+// Builds the report of the period [from, to]. Every amount is converted with the rate of
+// its date: the issue date of invoices and the spent date of expenses and time entries.
+func (r *CurrencyReporter) Report(from, to time.Time) (CurrencyReport, error) {
+	report := CurrencyReport{
+		BaseCurrency: r.baseCurrency,
+		From:         from,
+		To:           to,
+		Invoiced:     r.newTotals(),
+		Outstanding:  r.newTotals(),
+		Expenses:     r.newTotals(),
+		BillableTime: r.newTotals(),
+	}
+
+	invoices, err := r.client.Invoices.GetAllPages(HarvestCollectionParams{From: from, To: to})
+
+	if err != nil {
+		return report, err
+	}
+
+	for _, invoice := range invoices {
+		if invoice.State == InvoiceStateDraft {
+			continue
+		}
+
+		if err := r.add(&report.Invoiced, NewMoney(invoice.Amount, invoice.Currency), invoice.IssueDate.Time); err != nil {
+			return report, err
+		}
+
+		if err := r.add(&report.Outstanding, NewMoney(invoice.DueAmount, invoice.Currency), invoice.IssueDate.Time); err != nil {
+			return report, err
+		}
+	}
+
+	expenses, err := r.client.Expenses.GetAllPages(HarvestCollectionParams{From: from, To: to})
+
+	if err != nil {
+		return report, err
+	}
+
+	for _, expense := range expenses {
+		if err := r.add(&report.Expenses, NewMoney(expense.TotalCost, expense.Client.Currency), expense.SpentDate.Time); err != nil {
+			return report, err
+		}
+	}
+
+	entries, err := r.client.TimeEntries.GetAllPages(GetTimeEntriesParams{FromDate: OptionalTime(from), ToDate: OptionalTime(to)})
+
+	if err != nil {
+		return report, err
+	}
+
+	for _, entry := range entries {
+		amount := entry.billableAmount()
+
+		if amount.IsZero() {
+			continue
+		}
+
+		if err := r.add(&report.BillableTime, NewMoney(amount, entry.Client.Currency), entry.SpentDate.Time); err != nil {
+			return report, err
+		}
+	}
+
+	return report, nil
+}
+
+func (r *CurrencyReporter) newTotals() CurrencyTotals {
+	return CurrencyTotals{
+		ByCurrency: make(map[Currency]Money),
+		Converted:  make(map[Currency]Money),
+		Total:      NewMoney(decimal.Zero, r.baseCurrency),
+	}
+}
+
+func (r *CurrencyReporter) add(totals *CurrencyTotals, amount Money, on time.Time) error {
+	converted, err := amount.Convert(r.baseCurrency, r.rates, on)
+
+	if err != nil {
+		return err
+	}
+
+	original, ok := totals.ByCurrency[amount.Currency]
+
+	if !ok {
+		original = NewMoney(decimal.Zero, amount.Currency)
+	}
+
+	base, ok := totals.Converted[amount.Currency]
+
+	if !ok {
+		base = NewMoney(decimal.Zero, r.baseCurrency)
+	}
+
+	totals.ByCurrency[amount.Currency] = Money{Amount: original.Amount.Add(amount.Amount), Currency: amount.Currency}
+	totals.Converted[amount.Currency] = Money{Amount: base.Amount.Add(converted.Amount), Currency: r.baseCurrency}
+
+	// Sum the unrounded amounts, so rounding errors don't add up
+	total := decimal.Zero
+
+	for _, m := range totals.Converted {
+		total = total.Add(m.Amount)
+	}
+
+	totals.Total = NewMoney(total, r.baseCurrency).Round()
+	return nil
+}
@@ -0,0 +1,308 @@
+package randall
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+)
+
+// A reminder sent for invoices overdue by at least a number of days.
+type DunningStage struct {
+	// Identifies the stage in the reminder log, e.g. "first-reminder".
+	Name        string
+	DaysOverdue int
+	// The subject and body of the message, executed with a DunningTemplateData. Harvest's
+	// default subject and body are used when nil.
+	Subject     *template.Template
+	Body        *template.Template
+	AttachPdf   bool
+	SendMeACopy bool
+}
+
+// The data dunning templates are executed with.
+type DunningTemplateData struct {
+	Invoice     Invoice
+	Client      ClientRef
+	Stage       string
+	DaysOverdue int
+	// The due amount formatted with the minor units of the invoice's currency.
+	DueAmount string
+	// The contacts the reminder is sent to.
+	Contacts []Contact
+}
+
+// Records which reminders were sent for which invoices.
+type ReminderLog interface {
+	// Returns whether the reminder of the stage was sent for the invoice.
+	WasSent(invoiceId uint, stage string) (bool, error)
+	// Records that the reminder of the stage was sent for the invoice.
+	RecordSent(invoiceId uint, stage string, sentAt time.Time) error
+}
+
+// A ReminderLog kept in memory.
+type MemoryReminderLog struct {
+	mu   sync.Mutex
+	sent map[string]time.Time
+}
+
+// A ReminderLog that keeps the sent reminders in a JSON file.
+type FileReminderLog struct {
+	file jsonFileMap[string, time.Time]
+}
+
+// A reminder selected by a dunning run.
+type DunningReminder struct {
+	Invoice     Invoice
+	Stage       string
+	DaysOverdue int
+	Recipients  []MessageRecipient
+	// The rendered subject and body. Empty if Harvest's default is used.
+	Subject string
+	Body    string
+	// Whether the reminder was sent. Always false for dry runs.
+	Sent       bool
+	SkipReason string
+	Err        error
+}
+
+// Sends templated reminders for overdue invoices to the contacts of their clients.
+type DunningEngine struct {
+	client *HarvestClient
+	log    ReminderLog
+	// The reminder stages. Every invoice is sent the reminder of the latest stage it
+	// reached, once.
+	Stages []DunningStage
+	// Selects and renders reminders without sending or recording them.
+	DryRun bool
+}
+
+func NewMemoryReminderLog() *MemoryReminderLog {
+	return &MemoryReminderLog{
+		sent: make(map[string]time.Time),
+	}
+}
+
+func (l *MemoryReminderLog) WasSent(invoiceId uint, stage string) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	_, ok := l.sent[reminderKey(invoiceId, stage)]
+	return ok, nil
+}
+
+func (l *MemoryReminderLog) RecordSent(invoiceId uint, stage string, sentAt time.Time) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.sent[reminderKey(invoiceId, stage)] = sentAt
+	return nil
+}
+
+// Initializes a FileReminderLog backed by the file at path. The file is created on the
+// first call to RecordSent.
+func NewFileReminderLog(path string) *FileReminderLog {
+	return &FileReminderLog{
+		file: jsonFileMap[string, time.Time]{path: path},
+	}
+}
+
+func (l *FileReminderLog) WasSent(invoiceId uint, stage string) (bool, error) {
+	_, ok, err := l.file.get(reminderKey(invoiceId, stage))
+	return ok, err
+}
+
+func (l *FileReminderLog) RecordSent(invoiceId uint, stage string, sentAt time.Time) error {
+	return l.file.set(reminderKey(invoiceId, stage), sentAt)
+}
+
+func reminderKey(invoiceId uint, stage string) string {
+	return strconv.FormatUint(uint64(invoiceId), 10) + "/" + stage
+}
+
+// Initializes a new DunningEngine recording sent reminders in log.
+func NewDunningEngine(client *HarvestClient, log ReminderLog, stages ...DunningStage) *DunningEngine {
+	return &DunningEngine{
+		client: client,
+		log:    log,
+		Stages: stages,
+	}
+}
+
+// Scans the open invoices overdue as of the day of asOf and sends the reminder of the
+// latest stage every invoice reached, unless it was sent before. Failures to send a
+// reminder are reported in its Err and don't stop the run.
+func (e *DunningEngine) Run(asOf time.Time) ([]DunningReminder, error) {
+	if len(e.Stages) == 0 {
+		return nil, errors.New("no dunning stages are configured")
+	}
+
+	stages := make([]DunningStage, len(e.Stages))
+	copy(stages, e.Stages)
+
+	sort.SliceStable(stages, func(i, j int) bool {
+		return stages[i].DaysOverdue > stages[j].DaysOverdue
+	})
+
+	day := time.Date(asOf.Year(), asOf.Month(), asOf.Day(), 0, 0, 0, 0, time.UTC)
+	invoices, err := e.client.Invoices.GetAllPages(HarvestCollectionParams{State: string(InvoiceStateOpen)})
+
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(invoices, func(i, j int) bool {
+		return invoices[i].DueDate.Before(invoices[j].DueDate.Time)
+	})
+
+	contacts := make(map[uint][]Contact)
+	var reminders []DunningReminder
+
+	for _, invoice := range invoices {
+		if !invoice.DueAmount.IsPositive() || invoice.DueDate.IsZero() {
+			continue
+		}
+
+		daysOverdue := int(day.Sub(invoice.DueDate.Time) / (24 * time.Hour))
+		stage := latestDunningStage(stages, daysOverdue)
+
+		if stage == nil {
+			continue
+		}
+
+		sent, err := e.log.WasSent(invoice.Id, stage.Name)
+
+		if err != nil {
+			return reminders, err
+		}
+
+		if sent {
+			continue
+		}
+
+		reminder := DunningReminder{Invoice: invoice, Stage: stage.Name, DaysOverdue: daysOverdue}
+
+		clientContacts, ok := contacts[invoice.Client.Id]
+
+		if !ok {
+			clientContacts, err = e.clientContacts(invoice.Client.Id)
+
+			if err != nil {
+				return reminders, err
+			}
+
+			contacts[invoice.Client.Id] = clientContacts
+		}
+
+		if len(clientContacts) == 0 {
+			reminder.SkipReason = fmt.Sprintf("client %s has no contacts with an email address", invoice.Client.Name)
+			reminders = append(reminders, reminder)
+			continue
+		}
+
+		for _, c := range clientContacts {
+			name := strings.TrimSpace(c.FirstName + " " + c.LastName)
+			reminder.Recipients = append(reminder.Recipients, MessageRecipient{Email: c.Email, Name: OptionalString(name)})
+		}
+
+		data := DunningTemplateData{
+			Invoice:     invoice,
+			Client:      invoice.Client,
+			Stage:       stage.Name,
+			DaysOverdue: daysOverdue,
+			DueAmount:   formatCurrencyAmount(invoice.DueAmount, invoice.Currency),
+			Contacts:    clientContacts,
+		}
+
+		if reminder.Subject, err = executeDunningTemplate(stage.Subject, data); err == nil {
+			reminder.Body, err = executeDunningTemplate(stage.Body, data)
+		}
+
+		if err != nil {
+			reminder.Err = err
+		} else if !e.DryRun {
+			reminder.Err = e.send(&reminder, *stage)
+		}
+
+		reminders = append(reminders, reminder)
+	}
+
+	return reminders, nil
+}
+
+func (e *DunningEngine) send(reminder *DunningReminder, stage DunningStage) error {
+	req := CreateInvoiceMessageRequest{
+		Recipients:  reminder.Recipients,
+		AttachPdf:   OptionalBool(stage.AttachPdf),
+		SendMeACopy: OptionalBool(stage.SendMeACopy),
+	}
+
+	if reminder.Subject != "" {
+		req.Subject = OptionalString(reminder.Subject)
+	}
+
+	if reminder.Body != "" {
+		req.Body = OptionalString(reminder.Body)
+	}
+
+	resp, err := e.client.Invoices.CreateInvoiceMessage(reminder.Invoice.Id, req)
+
+	if err == nil {
+		err = checkResponse(resp)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	reminder.Sent = true
+	return e.log.RecordSent(reminder.Invoice.Id, reminder.Stage, time.Now())
+}
+
+func (e *DunningEngine) clientContacts(clientId uint) ([]Contact, error) {
+	all, err := e.client.Contacts.GetAllPages(HarvestCollectionParams{ClientId: OptionalUInt(clientId)})
+
+	if err != nil {
+		return nil, err
+	}
+
+	var contacts []Contact
+
+	for _, c := range all {
+		if c.Email != "" {
+			contacts = append(contacts, c)
+		}
+	}
+
+	return contacts, nil
+}
+
+// Returns the stage with the most days overdue the invoice reached. stages are sorted by
+// days overdue, descending.
+func latestDunningStage(stages []DunningStage, daysOverdue int) *DunningStage {
+	for i, stage := range stages {
+		if daysOverdue >= stage.DaysOverdue && daysOverdue > 0 {
+			return &stages[i]
+		}
+	}
+
+	return nil
+}
+
+func executeDunningTemplate(t *template.Template, data DunningTemplateData) (string, error) {
+	if t == nil {
+		return "", nil
+	}
+
+	var b strings.Builder
+
+	if err := t.Execute(&b, data); err != nil {
+		return "", err
+	}
+
+	return b.String(), nil
+}
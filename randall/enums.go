@@ -0,0 +1,54 @@
+package randall
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Returns the value of values equal to s, ignoring case and surrounding whitespace, or the
+// trimmed s and an error if there is none.
+func parseEnum[T ~string](kind string, values []T, s string) (T, error) {
+	s = strings.TrimSpace(s)
+
+	for _, v := range values {
+		if strings.EqualFold(string(v), s) {
+			return v, nil
+		}
+	}
+
+	return T(s), fmt.Errorf("invalid %s %q", kind, s)
+}
+
+func containsEnum[T ~string](values []T, v T) bool {
+	for _, value := range values {
+		if value == v {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Marshals an enum value as is. Unknown values are refused by the validation of the
+// requests they are sent in, not here, so they can still be encoded for other purposes.
+func marshalEnum[T ~string](v T) ([]byte, error) {
+	return json.Marshal(string(v))
+}
+
+// Unmarshals an enum value, normalizing known values. Unknown values are kept as is, so
+// values Harvest adds later don't fail decoding its responses.
+func unmarshalEnum[T ~string](b []byte, parse func(string) (T, error)) (T, error) {
+	var s string
+
+	if err := json.Unmarshal(b, &s); err != nil {
+		return "", err
+	}
+
+	if s == "" {
+		return "", nil
+	}
+
+	v, _ := parse(s)
+	return v, nil
+}
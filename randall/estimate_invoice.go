@@ -0,0 +1,177 @@
+package randall
+
+import (
+	"fmt"
+
+	"github.com/shopspring/decimal"
+)
+
+// Options of converting an estimate into an invoice.
+type EstimateInvoiceOptions struct {
+	// The percentage of the estimate's line items to bill, e.g. 50 for a deposit. Defaults
+	// to 100.
+	Percentage *decimal.Decimal
+	// Only bills the line items with these ids. Defaults to every line item.
+	LineItemIds []uint
+	// Marks the estimate accepted once the invoice was created. If that fails the invoice
+	// is deleted again.
+	MarkAccepted bool
+	// The invoice to create. Its client, estimate, currency, taxes, discount and line items
+	// are taken from the estimate. The purchase order, subject and notes default to the
+	// estimate's.
+	Invoice CreateFreeFormInvoiceRequest
+}
+
+// The result of converting an estimate into an invoice.
+type EstimateConversion struct {
+	Estimate Estimate
+	Invoice  Invoice
+	// Whether the estimate was marked accepted by the conversion.
+	Accepted bool
+}
+
+// Creates a free-form invoice linked to an estimate from the estimate's line items, and
+// optionally marks the estimate accepted. If a step fails the steps completed before are
+// rolled back, so either the whole conversion succeeds or nothing changes.
+func ConvertEstimateToInvoice(client *HarvestClient, estimateId uint, opts EstimateInvoiceOptions) (EstimateConversion, error) {
+	var conversion EstimateConversion
+	resp, err := client.Estimates.Get(estimateId)
+
+	if err := DecodeResponse(resp, err, &conversion.Estimate); err != nil {
+		return conversion, err
+	}
+
+	estimate := conversion.Estimate
+	req, err := invoiceRequestFromEstimate(estimate, opts)
+
+	if err != nil {
+		return conversion, err
+	}
+
+	resp, err = client.Invoices.CreateFreeForm(req)
+
+	if err := DecodeResponse(resp, err, &conversion.Invoice); err != nil {
+		return conversion, fmt.Errorf("creating invoice for estimate %d: %w", estimateId, err)
+	}
+
+	if !opts.MarkAccepted || estimate.State == EstimateStateAccepted {
+		return conversion, nil
+	}
+
+	resp, err = client.Estimates.MarkEstimateAccepted(estimateId)
+
+	if err == nil {
+		err = checkResponse(resp)
+	}
+
+	if err != nil {
+		err = fmt.Errorf("marking estimate %d accepted: %w", estimateId, err)
+		invoiceId := conversion.Invoice.Id
+		conversion.Invoice = Invoice{}
+
+		resp, rollbackErr := client.Invoices.Delete(invoiceId)
+
+		if rollbackErr == nil {
+			rollbackErr = checkResponse(resp)
+		}
+
+		if rollbackErr != nil {
+			return conversion, fmt.Errorf("%w; rolling back invoice %d failed: %v", err, invoiceId, rollbackErr)
+		}
+
+		return conversion, err
+	}
+
+	conversion.Accepted = true
+	return conversion, nil
+}
+
+func invoiceRequestFromEstimate(estimate Estimate, opts EstimateInvoiceOptions) (CreateFreeFormInvoiceRequest, error) {
+	percentage := hundred
+
+	if opts.Percentage != nil {
+		percentage = *opts.Percentage
+	}
+
+	if !percentage.IsPositive() || percentage.GreaterThan(hundred) {
+		return CreateFreeFormInvoiceRequest{}, fmt.Errorf("percentage must be greater than 0 and at most 100, got %s", percentage)
+	}
+
+	units, err := CurrencyMinorUnits(estimate.Currency)
+
+	if err != nil {
+		return CreateFreeFormInvoiceRequest{}, err
+	}
+
+	req := opts.Invoice
+	req.ClientId = estimate.Client.Id
+	req.EstimateId = OptionalUInt(estimate.Id)
+	req.Currency = Optional(estimate.Currency)
+	req.Tax = estimate.Tax
+	req.Tax2 = estimate.Tax2
+	req.Discount = estimate.Discount
+	req.LineItems = nil
+
+	if req.PurchaseOrder == nil && estimate.PurchaseOrder != "" {
+		req.PurchaseOrder = OptionalString(estimate.PurchaseOrder)
+	}
+
+	if req.Subject == nil && estimate.Subject != "" {
+		req.Subject = OptionalString(estimate.Subject)
+	}
+
+	if req.Notes == nil && estimate.Notes != "" {
+		req.Notes = OptionalString(estimate.Notes)
+	}
+
+	selected := make(map[uint]bool, len(opts.LineItemIds))
+	billed := make(map[uint]bool, len(estimate.LineItems))
+
+	for _, id := range opts.LineItemIds {
+		selected[id] = true
+	}
+
+	for _, item := range estimate.LineItems {
+		if len(selected) > 0 && !selected[item.Id] {
+			continue
+		}
+
+		billed[item.Id] = true
+
+		line := CreateEstimateLineItemRequest{
+			Kind:        item.Kind,
+			Description: OptionalString(item.Description),
+			Taxed:       OptionalBool(item.Taxed),
+			Taxed2:      OptionalBool(item.Taxed2),
+		}
+
+		// Line items take whole quantities, so fractional quantities and partially billed
+		// line items are billed as a single unit of the amount due
+		if percentage.Equal(hundred) && item.Quantity.IsInteger() && !item.Quantity.IsNegative() {
+			line.Quantity = OptionalUInt(uint(item.Quantity.IntPart()))
+			line.UnitPrice = OptionalDecimal(item.UnitPrice)
+		} else {
+			amount := item.Quantity.Mul(item.UnitPrice).Mul(percentage).Div(hundred).Round(units)
+			line.Quantity = OptionalUInt(1)
+			line.UnitPrice = OptionalDecimal(amount)
+
+			if !percentage.Equal(hundred) {
+				line.Description = OptionalString(fmt.Sprintf("%s (%s%% of %s)", item.Description, percentage, item.Amount.StringFixed(units)))
+			}
+		}
+
+		req.LineItems = append(req.LineItems, line)
+	}
+
+	for _, id := range opts.LineItemIds {
+		if !billed[id] {
+			return req, fmt.Errorf("estimate %d has no line item %d", estimate.Id, id)
+		}
+	}
+
+	if len(req.LineItems) == 0 {
+		return req, fmt.Errorf("estimate %d has no line items to bill", estimate.Id)
+	}
+
+	return req, nil
+}
@@ -7,6 +7,18 @@ import (
 	"github.com/shopspring/decimal"
 )
 
+// The state of an estimate.
+type EstimateState string
+
+const (
+	EstimateStateDraft    EstimateState = "draft"
+	EstimateStateSent     EstimateState = "sent"
+	EstimateStateAccepted EstimateState = "accepted"
+	EstimateStateDeclined EstimateState = "declined"
+)
+
+var estimateStates = []EstimateState{EstimateStateDraft, EstimateStateSent, EstimateStateAccepted, EstimateStateDeclined}
+
 // Encapsulates the Harvest API methods under /expenses
 type EstimatesApi struct {
 	estimatesBaseUrl              string
@@ -23,13 +35,13 @@ type CreateEstimateRequest struct {
 	Discount      *decimal.Decimal                `json:"discount,omitempty"`
 	Subject       *string                         `json:"subject,omitempty"`
 	Notes         *string                         `json:"notes,omitempty"`
-	Currency      *string                         `json:"currency,omitempty"`
+	Currency      *Currency                       `json:"currency,omitempty"`
 	IssueDate     time.Time                       `json:"issue_date,omitempty"`
 	LineItems     []CreateEstimateLineItemRequest `json:"line_items,omitempty"`
 }
 
 type CreateEstimateLineItemRequest struct {
-	Kind        string           `json:"kind"`
+	Kind        LineItemKind     `json:"kind"`
 	Description *string          `json:"description,omitempty"`
 	Quantity    *uint            `json:"quantity,omitempty"`
 	UnitPrice   *decimal.Decimal `json:"unit_price,omitempty"`
@@ -46,14 +58,14 @@ type UpdateEstimateRequest struct {
 	Discount      *decimal.Decimal                `json:"discount,omitempty"`
 	Subject       *string                         `json:"subject,omitempty"`
 	Notes         *string                         `json:"notes,omitempty"`
-	Currency      *string                         `json:"currency,omitempty"`
+	Currency      *Currency                       `json:"currency,omitempty"`
 	IssueDate     time.Time                       `json:"issue_date,omitempty"`
 	LineItems     []UpdateEstimateLineItemRequest `json:"line_items,omitempty"`
 }
 
 type UpdateEstimateLineItemRequest struct {
 	Id          *uint            `json:"id,omitempty"`
-	Kind        *string          `json:"kind,omitempty"`
+	Kind        *LineItemKind    `json:"kind,omitempty"`
 	Description *string          `json:"description,omitempty"`
 	Quantity    *uint            `json:"quantity,omitempty"`
 	UnitPrice   *decimal.Decimal `json:"unit_price,omitempty"`
@@ -62,6 +74,46 @@ type UpdateEstimateLineItemRequest struct {
 	Destroy     *bool            `json:"_destroy,omitempty"`
 }
 
+// An estimate as returned by the Harvest API.
+type Estimate struct {
+	Id             uint               `json:"id"`
+	Client         ClientRef          `json:"client"`
+	LineItems      []EstimateLineItem `json:"line_items"`
+	Creator        ObjectRef          `json:"creator"`
+	ClientKey      string             `json:"client_key"`
+	Number         string             `json:"number"`
+	PurchaseOrder  string             `json:"purchase_order"`
+	Amount         decimal.Decimal    `json:"amount"`
+	Tax            *decimal.Decimal   `json:"tax"`
+	TaxAmount      decimal.Decimal    `json:"tax_amount"`
+	Tax2           *decimal.Decimal   `json:"tax2"`
+	Tax2Amount     decimal.Decimal    `json:"tax2_amount"`
+	Discount       *decimal.Decimal   `json:"discount"`
+	DiscountAmount decimal.Decimal    `json:"discount_amount"`
+	Subject        string             `json:"subject"`
+	Notes          string             `json:"notes"`
+	Currency       Currency           `json:"currency"`
+	State          EstimateState      `json:"state"`
+	IssueDate      HarvestDate        `json:"issue_date"`
+	SentAt         *time.Time         `json:"sent_at"`
+	AcceptedAt     *time.Time         `json:"accepted_at"`
+	DeclinedAt     *time.Time         `json:"declined_at"`
+	CreatedAt      time.Time          `json:"created_at"`
+	UpdatedAt      time.Time          `json:"updated_at"`
+}
+
+// A line item of an estimate as returned by the Harvest API.
+type EstimateLineItem struct {
+	Id          uint            `json:"id"`
+	Kind        LineItemKind    `json:"kind"`
+	Description string          `json:"description"`
+	Quantity    decimal.Decimal `json:"quantity"`
+	UnitPrice   decimal.Decimal `json:"unit_price"`
+	Amount      decimal.Decimal `json:"amount"`
+	Taxed       bool            `json:"taxed"`
+	Taxed2      bool            `json:"taxed2"`
+}
+
 type CreateEstimateMessageRequest struct {
 	Recipients  []MessageRecipient `json:"recipients"`
 	Subject     *string            `json:"subject,omitempty"`
@@ -70,6 +122,29 @@ type CreateEstimateMessageRequest struct {
 	EventType   *string            `json:"event_type,omitempty"`
 }
 
+// Parses the state of an estimate, ignoring case.
+func ParseEstimateState(s string) (EstimateState, error) {
+	return parseEnum("estimate state", estimateStates, s)
+}
+
+// Returns whether the estimate state is known to Harvest.
+func (s EstimateState) Valid() bool {
+	return containsEnum(estimateStates, s)
+}
+
+func (s EstimateState) String() string {
+	return string(s)
+}
+
+func (s EstimateState) MarshalJSON() ([]byte, error) {
+	return marshalEnum(s)
+}
+
+func (s *EstimateState) UnmarshalJSON(b []byte) (err error) {
+	*s, err = unmarshalEnum(b, ParseEstimateState)
+	return err
+}
+
 func newEstimatesV2(client *internalClient) EstimatesApi {
 	return EstimatesApi{
 		estimatesBaseUrl:              "v2/estimates",
@@ -82,6 +157,11 @@ func (api EstimatesApi) GetAll(params ...HarvestCollectionParams) (HarvestRespon
 	return api.client.doGet(api.estimatesBaseUrl, getOptionalCollectionParams(params))
 }
 
+// Retrieves every page of estimates matching params as typed Estimate objects.
+func (api EstimatesApi) GetAllPages(params ...HarvestCollectionParams) ([]Estimate, error) {
+	return getAllCollectionPages[Estimate]("estimates", params, api.GetAll)
+}
+
 func (api EstimatesApi) Get(estimateId uint) (HarvestResponse, error) {
 	return api.client.doGet(fmt.Sprintf("%s/%d", api.estimatesBaseUrl, estimateId))
 }
@@ -136,8 +216,8 @@ func (api EstimatesApi) ReopenClosedEstimate(estimateId uint) (HarvestResponse,
 	)
 }
 
-func (api EstimatesApi) DeleteEstimateMessage(estimateId uint) (HarvestResponse, error) {
-	return api.client.doDelete(fmt.Sprintf("%s/%d/messages", api.estimatesBaseUrl, estimateId))
+func (api EstimatesApi) DeleteEstimateMessage(estimateId, estimateMessageId uint) (HarvestResponse, error) {
+	return api.client.doDelete(fmt.Sprintf("%s/%d/messages/%d", api.estimatesBaseUrl, estimateId, estimateMessageId))
 }
 
 func (api EstimatesApi) GetAllEstimateItemCategories(params ...HarvestCollectionParams) (HarvestResponse, error) {
@@ -6,6 +6,7 @@ import (
 	"os"
 	"path/filepath"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/shopspring/decimal"
@@ -56,6 +57,35 @@ type UpdateExpenseCategoryRequest struct {
 	IsActive  *bool            `json:"is_active,omitempty"`
 }
 
+// An expense as returned by the Harvest API.
+type Expense struct {
+	Id              uint            `json:"id"`
+	SpentDate       HarvestDate     `json:"spent_date"`
+	User            ObjectRef       `json:"user"`
+	Client          ClientRef       `json:"client"`
+	Project         ProjectRef      `json:"project"`
+	ExpenseCategory ObjectRef       `json:"expense_category"`
+	Receipt         *ExpenseReceipt `json:"receipt"`
+	Invoice         *InvoiceRef     `json:"invoice"`
+	Notes           string          `json:"notes"`
+	Units           decimal.Decimal `json:"units"`
+	TotalCost       decimal.Decimal `json:"total_cost"`
+	Billable        bool            `json:"billable"`
+	IsClosed        bool            `json:"is_closed"`
+	IsLocked        bool            `json:"is_locked"`
+	IsBilled        bool            `json:"is_billed"`
+	LockedReason    string          `json:"locked_reason"`
+	CreatedAt       time.Time       `json:"created_at"`
+	UpdatedAt       time.Time       `json:"updated_at"`
+}
+
+type ExpenseReceipt struct {
+	Url         string `json:"url"`
+	FileName    string `json:"file_name"`
+	FileSize    uint   `json:"file_size"`
+	ContentType string `json:"content_type"`
+}
+
 func newExpensesV2(client *internalClient) ExpensesApi {
 	return ExpensesApi{
 		expensesBaseUrl:          "v2/expenses",
@@ -68,12 +98,21 @@ func (api ExpensesApi) GetAll(params ...HarvestCollectionParams) (HarvestRespons
 	return api.client.doGet(api.expensesBaseUrl, getOptionalCollectionParams(params))
 }
 
+// Retrieves every page of expenses matching params as typed Expense objects.
+func (api ExpensesApi) GetAllPages(params ...HarvestCollectionParams) ([]Expense, error) {
+	return getAllCollectionPages[Expense]("expenses", params, api.GetAll)
+}
+
 func (api ExpensesApi) Get(expenseId uint) (HarvestResponse, error) {
 	return api.client.doGet(fmt.Sprintf("%s/%d", api.expensesBaseUrl, expenseId))
 }
 
 func (api ExpensesApi) Create(req CreateExpenseRequest) (HarvestResponse, error) {
 	if req.Receipt != nil {
+		if err := api.client.validateBody(req); err != nil {
+			return HarvestResponse{}, err
+		}
+
 		multipart, err := req.multipartData()
 		if err != nil {
 			return HarvestResponse{}, err
@@ -86,6 +125,10 @@ func (api ExpensesApi) Create(req CreateExpenseRequest) (HarvestResponse, error)
 
 func (api ExpensesApi) Update(expenseId uint, req UpdateExpenseRequest) (HarvestResponse, error) {
 	if req.Receipt != nil {
+		if err := api.client.validateBody(req); err != nil {
+			return HarvestResponse{}, err
+		}
+
 		multipart, err := req.multipartData()
 		if err != nil {
 			return HarvestResponse{}, err
@@ -107,11 +150,11 @@ func (api ExpensesApi) GetExpenseCategory(expenseCategoryId uint) (HarvestRespon
 	return api.client.doGet(fmt.Sprintf("%s/%d", api.expenseCategoriesBaseUrl, expenseCategoryId))
 }
 
-func (api ExpensesApi) CreateExpenseCategory(req CreateExpenseRequest) (HarvestResponse, error) {
+func (api ExpensesApi) CreateExpenseCategory(req CreateExpenseCategoryRequest) (HarvestResponse, error) {
 	return api.client.doPost(api.expenseCategoriesBaseUrl, req)
 }
 
-func (api ExpensesApi) UpdateExpenseCategory(expenseCategoryId uint, req UpdateExpenseRequest) (HarvestResponse, error) {
+func (api ExpensesApi) UpdateExpenseCategory(expenseCategoryId uint, req UpdateExpenseCategoryRequest) (HarvestResponse, error) {
 	return api.client.doPatch(fmt.Sprintf("%s/%d", api.expenseCategoriesBaseUrl, expenseCategoryId), req)
 }
 
@@ -213,7 +256,7 @@ func isValidReceipt(receiptPath string) error {
 	}
 
 	validExts := []string{"pdf", "png", "jpg", "gif"}
-	ext := filepath.Ext(receiptPath)
+	ext := strings.ToLower(strings.TrimPrefix(filepath.Ext(receiptPath), "."))
 	isValidExt := false
 
 	for _, validExt := range validExts {
@@ -0,0 +1,386 @@
+package randall
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+
+	"github.com/shopspring/decimal"
+)
+
+// How the rows of a CSV export are grouped into subtotals.
+type CsvGroupBy string
+
+const (
+	CsvGroupByNone    CsvGroupBy = ""
+	CsvGroupByClient  CsvGroupBy = "client"
+	CsvGroupByProject CsvGroupBy = "project"
+	CsvGroupByUser    CsvGroupBy = "user"
+)
+
+type CsvExportOptions struct {
+	// The names of the columns to write, in order. Defaults to every column. See
+	// TimeEntryCsvColumns and ExpenseCsvColumns for the available names.
+	Columns []string
+	// Groups rows and writes a subtotal row after each group and a total row at the end.
+	GroupBy CsvGroupBy
+	// The number of decimal places amounts are written with. When nil amounts are
+	// written with the full precision sent by Harvest.
+	DecimalPlaces *int32
+}
+
+// A column of a CSV export of records of type T.
+type CsvColumn[T any] struct {
+	Name string
+	// Returns the value of the column for a record. Decimal values are formatted by the exporter.
+	Value func(record T) interface{}
+	// Whether the column is summed in subtotal and total rows. Columns are summed per
+	// currency, so amounts in different currencies are never added up.
+	Summed bool
+}
+
+// How writeCsv groups, orders and sums a record.
+type csvRecordKeys struct {
+	// Identifies the group of the record, e.g. the id of its client.
+	group string
+	// The name of the group written in subtotal rows.
+	label string
+	// Orders the records of a group.
+	sort string
+	// The currency of the record's amounts.
+	currency Currency
+}
+
+// The sums of the summed columns of a group, per currency.
+type csvSums map[Currency][]decimal.Decimal
+
+// The columns available when exporting time entries, in their default order.
+var TimeEntryCsvColumns = []CsvColumn[TimeEntry]{
+	{Name: "id", Value: func(e TimeEntry) interface{} { return e.Id }},
+	{Name: "date", Value: func(e TimeEntry) interface{} { return e.SpentDate.Format("2006-01-02") }},
+	{Name: "user", Value: func(e TimeEntry) interface{} { return e.User.Name }},
+	{Name: "client", Value: func(e TimeEntry) interface{} { return e.Client.Name }},
+	{Name: "project", Value: func(e TimeEntry) interface{} { return e.Project.Name }},
+	{Name: "project_code", Value: func(e TimeEntry) interface{} { return e.Project.Code }},
+	{Name: "task", Value: func(e TimeEntry) interface{} { return e.Task.Name }},
+	{Name: "notes", Value: func(e TimeEntry) interface{} { return e.Notes }},
+	{Name: "started_time", Value: func(e TimeEntry) interface{} { return e.StartedTime }},
+	{Name: "ended_time", Value: func(e TimeEntry) interface{} { return e.EndedTime }},
+	{Name: "hours", Value: func(e TimeEntry) interface{} { return e.Hours }, Summed: true},
+	{Name: "rounded_hours", Value: func(e TimeEntry) interface{} { return e.RoundedHours }, Summed: true},
+	{Name: "billable", Value: func(e TimeEntry) interface{} { return e.Billable }},
+	{Name: "billed", Value: func(e TimeEntry) interface{} { return e.IsBilled }},
+	{Name: "billable_rate", Value: func(e TimeEntry) interface{} { return optionalDecimalValue(e.BillableRate) }},
+	{Name: "billable_amount", Value: func(e TimeEntry) interface{} { return e.billableAmount() }, Summed: true},
+	{Name: "cost_rate", Value: func(e TimeEntry) interface{} { return optionalDecimalValue(e.CostRate) }},
+	{Name: "currency", Value: func(e TimeEntry) interface{} { return e.Client.Currency }},
+	{Name: "external_reference", Value: func(e TimeEntry) interface{} {
+		if e.ExternalReference == nil {
+			return ""
+		}
+
+		return e.ExternalReference.Permalink
+	}},
+}
+
+// The columns available when exporting expenses, in their default order.
+var ExpenseCsvColumns = []CsvColumn[Expense]{
+	{Name: "id", Value: func(e Expense) interface{} { return e.Id }},
+	{Name: "date", Value: func(e Expense) interface{} { return e.SpentDate.Format("2006-01-02") }},
+	{Name: "user", Value: func(e Expense) interface{} { return e.User.Name }},
+	{Name: "client", Value: func(e Expense) interface{} { return e.Client.Name }},
+	{Name: "project", Value: func(e Expense) interface{} { return e.Project.Name }},
+	{Name: "project_code", Value: func(e Expense) interface{} { return e.Project.Code }},
+	{Name: "category", Value: func(e Expense) interface{} { return e.ExpenseCategory.Name }},
+	{Name: "notes", Value: func(e Expense) interface{} { return e.Notes }},
+	{Name: "units", Value: func(e Expense) interface{} { return e.Units }, Summed: true},
+	{Name: "total_cost", Value: func(e Expense) interface{} { return e.TotalCost }, Summed: true},
+	{Name: "currency", Value: func(e Expense) interface{} { return e.Client.Currency }},
+	{Name: "billable", Value: func(e Expense) interface{} { return e.Billable }},
+	{Name: "billed", Value: func(e Expense) interface{} { return e.IsBilled }},
+	{Name: "receipt_url", Value: func(e Expense) interface{} {
+		if e.Receipt == nil {
+			return ""
+		}
+
+		return e.Receipt.Url
+	}},
+}
+
+// Retrieves every time entry matching params and writes them to w as CSV.
+func ExportTimeEntriesCsv(w io.Writer, api TimeEntriesApi, params GetTimeEntriesParams, opts CsvExportOptions) error {
+	entries, err := api.GetAllPages(params)
+
+	if err != nil {
+		return err
+	}
+
+	return writeCsv(w, entries, TimeEntryCsvColumns, opts, func(e TimeEntry) csvRecordKeys {
+		group, label := csvGroup(opts.GroupBy, e.Client, e.Project, e.User)
+		return csvRecordKeys{group: group, label: label, sort: e.SpentDate.Format("2006-01-02"), currency: e.Client.Currency}
+	})
+}
+
+// Retrieves every expense matching params and writes them to w as CSV.
+func ExportExpensesCsv(w io.Writer, api ExpensesApi, params HarvestCollectionParams, opts CsvExportOptions) error {
+	expenses, err := api.GetAllPages(params)
+
+	if err != nil {
+		return err
+	}
+
+	return writeCsv(w, expenses, ExpenseCsvColumns, opts, func(e Expense) csvRecordKeys {
+		group, label := csvGroup(opts.GroupBy, e.Client, e.Project, e.User)
+		return csvRecordKeys{group: group, label: label, sort: e.SpentDate.Format("2006-01-02"), currency: e.Client.Currency}
+	})
+}
+
+// Writes the records as CSV. When grouping, a subtotal row is written after every group
+// and total rows at the end, one per currency if the records are in several currencies.
+func writeCsv[T any](w io.Writer, records []T, available []CsvColumn[T], opts CsvExportOptions, keys func(T) csvRecordKeys) error {
+	columns, err := selectCsvColumns(available, opts.Columns)
+
+	if err != nil {
+		return err
+	}
+
+	switch opts.GroupBy {
+	case CsvGroupByNone, CsvGroupByClient, CsvGroupByProject, CsvGroupByUser:
+	default:
+		return fmt.Errorf("unknown CSV grouping %q", opts.GroupBy)
+	}
+
+	grouped := opts.GroupBy != CsvGroupByNone
+	// Subtotal and total rows are labelled in the first column that isn't summed. If every
+	// column is summed, a leading column is added for the labels.
+	labelColumn := -1
+
+	for i, column := range columns {
+		if !column.Summed {
+			labelColumn = i
+			break
+		}
+	}
+
+	labelled := !grouped || labelColumn >= 0
+	cw := csv.NewWriter(w)
+
+	header := make([]string, len(columns))
+
+	for i, column := range columns {
+		header[i] = column.Name
+	}
+
+	if !labelled {
+		header = append([]string{"total"}, header...)
+	}
+
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+
+	if grouped {
+		sort.SliceStable(records, func(i, j int) bool {
+			ki, kj := keys(records[i]), keys(records[j])
+
+			if ki.label != kj.label {
+				return ki.label < kj.label
+			}
+
+			if ki.group != kj.group {
+				return ki.group < kj.group
+			}
+
+			return ki.sort < kj.sort
+		})
+	}
+
+	writeSums := func(sums csvSums, label string) error {
+		for _, row := range csvTotalRows(columns, sums, label, labelColumn, opts) {
+			if err := cw.Write(row); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	subtotals := make(csvSums)
+	totals := make(csvSums)
+	var current csvRecordKeys
+
+	for i, record := range records {
+		k := keys(record)
+
+		if grouped && i > 0 && k.group != current.group {
+			if err := writeSums(subtotals, "Subtotal "+current.label); err != nil {
+				return err
+			}
+
+			subtotals = make(csvSums)
+		}
+
+		current = k
+		row := make([]string, len(columns))
+
+		for j, column := range columns {
+			value := column.Value(record)
+			row[j] = formatCsvValue(value, opts)
+
+			if d, ok := value.(decimal.Decimal); ok && column.Summed {
+				subtotals.add(k.currency, j, len(columns), d)
+				totals.add(k.currency, j, len(columns), d)
+			}
+		}
+
+		if !labelled {
+			row = append([]string{""}, row...)
+		}
+
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+
+	if grouped {
+		if len(records) > 0 {
+			if err := writeSums(subtotals, "Subtotal "+current.label); err != nil {
+				return err
+			}
+		}
+
+		if err := writeSums(totals, "Total"); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+func (s csvSums) add(currency Currency, column, columns int, d decimal.Decimal) {
+	if _, ok := s[currency]; !ok {
+		s[currency] = make([]decimal.Decimal, columns)
+	}
+
+	s[currency][column] = s[currency][column].Add(d)
+}
+
+func selectCsvColumns[T any](available []CsvColumn[T], names []string) ([]CsvColumn[T], error) {
+	if len(names) == 0 {
+		return available, nil
+	}
+
+	selected := make([]CsvColumn[T], 0, len(names))
+
+	for _, name := range names {
+		found := false
+
+		for _, column := range available {
+			if column.Name == name {
+				selected = append(selected, column)
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return nil, fmt.Errorf("unknown CSV column %q", name)
+		}
+	}
+
+	return selected, nil
+}
+
+// Returns one row with the sums per currency, sorted by currency, or a single row with no
+// sums if there are none. Rows of several currencies have the currency appended to their
+// label. labelColumn is the column the label is written to, -1 for a leading column.
+func csvTotalRows[T any](columns []CsvColumn[T], sums csvSums, label string, labelColumn int, opts CsvExportOptions) [][]string {
+	currencies := make([]Currency, 0, len(sums))
+
+	for currency := range sums {
+		currencies = append(currencies, currency)
+	}
+
+	sort.Slice(currencies, func(i, j int) bool {
+		return currencies[i] < currencies[j]
+	})
+
+	if len(currencies) == 0 {
+		currencies = append(currencies, "")
+	}
+
+	rows := make([][]string, len(currencies))
+
+	for r, currency := range currencies {
+		row := make([]string, len(columns))
+		rowLabel := label
+
+		if len(currencies) > 1 {
+			rowLabel = fmt.Sprintf("%s (%s)", label, currency)
+		}
+
+		for i, column := range columns {
+			switch {
+			case column.Summed && sums[currency] != nil:
+				row[i] = formatCsvValue(sums[currency][i], opts)
+			case column.Summed:
+				row[i] = formatCsvValue(decimal.Zero, opts)
+			case column.Name == "currency":
+				row[i] = string(currency)
+			}
+		}
+
+		if labelColumn < 0 {
+			row = append([]string{rowLabel}, row...)
+		} else {
+			row[labelColumn] = rowLabel
+		}
+
+		rows[r] = row
+	}
+
+	return rows
+}
+
+// Returns the id and the name of the client, project or user of a record to group by.
+func csvGroup(groupBy CsvGroupBy, client ClientRef, project ProjectRef, user ObjectRef) (string, string) {
+	switch groupBy {
+	case CsvGroupByClient:
+		return strconv.FormatUint(uint64(client.Id), 10), client.Name
+	case CsvGroupByProject:
+		return strconv.FormatUint(uint64(project.Id), 10), project.Name
+	case CsvGroupByUser:
+		return strconv.FormatUint(uint64(user.Id), 10), user.Name
+	default:
+		return "", ""
+	}
+}
+
+func formatCsvValue(value interface{}, opts CsvExportOptions) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case decimal.Decimal:
+		if opts.DecimalPlaces != nil {
+			return v.StringFixed(*opts.DecimalPlaces)
+		}
+
+		return v.String()
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// Returns the decimal value of d, or nil so an empty cell is written.
+func optionalDecimalValue(d *decimal.Decimal) interface{} {
+	if d == nil {
+		return nil
+	}
+
+	return *d
+}
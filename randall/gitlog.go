@@ -0,0 +1,292 @@
+package randall
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// A commit read from a local git repository.
+type GitCommit struct {
+	Hash        string
+	AuthorName  string
+	AuthorEmail string
+	// The author date of the commit.
+	Time time.Time
+	// The branch the commit was reached from, e.g. "main".
+	Branch  string
+	Subject string
+}
+
+// Maps the commits of a repository, optionally of matching branches only, to a project and task.
+type GitProjectMapping struct {
+	// The name of the repository's directory, e.g. "randall".
+	Repository string
+	// Only maps commits of branches matching the expression, if set.
+	BranchPattern *regexp.Regexp
+	ProjectId     uint
+	TaskId        uint
+	// A format string turning a commit hash into its URL, e.g.
+	// "https://github.com/calexa22/randall/commit/%s". Used as the external reference permalink
+	// of the session's first commit, which also identifies the time entry when upserting.
+	CommitUrl string
+}
+
+// Consecutive commits to the same project and task, none further apart than the session gap.
+type GitWorkSession struct {
+	Repository string
+	Commits    []GitCommit
+	// The time of the first commit minus the generator's lead time.
+	Start time.Time
+	// The time of the last commit.
+	End     time.Time
+	Mapping *GitProjectMapping
+}
+
+// A time entry generated from a work session, to be reviewed before creating it through
+// TimeEntriesApi.CreateViaDuration.
+type GitTimeEntryDraft struct {
+	Session GitWorkSession
+	// The generated time entry. Nil when the session was skipped.
+	Request    *CreateTimeEntryViaDurationRequest
+	SkipReason string
+}
+
+// Reconstructs time entries from the commit history of local git repositories.
+type GitTimeEntryGenerator struct {
+	// The repositories' mappings to projects and tasks. The first matching mapping wins.
+	Mappings []GitProjectMapping
+	// Commits further apart than this start a new work session. Defaults to 2 hours.
+	SessionGap time.Duration
+	// The time credited before the first commit of a session, to account for the work
+	// leading up to it. Defaults to 30 minutes, a negative value credits no lead time.
+	LeadTime time.Duration
+	// The location spent dates are determined in. Defaults to the local timezone.
+	Location *time.Location
+	// The user to generate the time entries for. Defaults to the authenticated user.
+	UserId *uint
+}
+
+const gitLogFieldSeparator = "\x1f"
+
+// Reads the commits of every branch of the repository at repoPath that were authored by
+// author, a pattern matched against the author's name and email, within [since, until].
+func ReadGitLog(repoPath, author string, since, until time.Time) ([]GitCommit, error) {
+	args := []string{
+		"-C", repoPath, "log", "--all", "--source", "--no-merges",
+		"--author=" + author,
+		"--format=" + strings.Join([]string{"%H", "%an", "%ae", "%aI", "%S", "%s"}, gitLogFieldSeparator),
+	}
+
+	if !since.IsZero() {
+		args = append(args, "--since="+since.Format(time.RFC3339))
+	}
+
+	if !until.IsZero() {
+		args = append(args, "--until="+until.Format(time.RFC3339))
+	}
+
+	var stderr bytes.Buffer
+	cmd := exec.Command("git", args...)
+	cmd.Stderr = &stderr
+
+	out, err := cmd.Output()
+
+	if err != nil {
+		return nil, fmt.Errorf("git log %s: %w: %s", repoPath, err, strings.TrimSpace(stderr.String()))
+	}
+
+	var commits []GitCommit
+
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		if line == "" {
+			continue
+		}
+
+		fields := strings.SplitN(line, gitLogFieldSeparator, 6)
+
+		if len(fields) != 6 {
+			return nil, fmt.Errorf("unexpected git log output %q", line)
+		}
+
+		t, err := time.Parse(time.RFC3339, fields[3])
+
+		if err != nil {
+			return nil, err
+		}
+
+		commits = append(commits, GitCommit{
+			Hash:        fields[0],
+			AuthorName:  fields[1],
+			AuthorEmail: fields[2],
+			Time:        t,
+			Branch:      gitBranchName(fields[4]),
+			Subject:     fields[5],
+		})
+	}
+
+	sort.SliceStable(commits, func(i, j int) bool {
+		return commits[i].Time.Before(commits[j].Time)
+	})
+
+	return commits, nil
+}
+
+// Reads the commits of every repository and drafts a time entry for every work session.
+// Sessions of repositories or branches without a mapping are skipped.
+func (g GitTimeEntryGenerator) Generate(repoPaths []string, author string, since, until time.Time) ([]GitTimeEntryDraft, error) {
+	var drafts []GitTimeEntryDraft
+
+	for _, repoPath := range repoPaths {
+		commits, err := ReadGitLog(repoPath, author, since, until)
+
+		if err != nil {
+			return nil, err
+		}
+
+		abs, err := filepath.Abs(repoPath)
+
+		if err != nil {
+			return nil, err
+		}
+
+		for _, session := range g.Sessions(filepath.Base(abs), commits) {
+			drafts = append(drafts, g.draft(session))
+		}
+	}
+
+	sort.SliceStable(drafts, func(i, j int) bool {
+		return drafts[i].Session.Start.Before(drafts[j].Session.Start)
+	})
+
+	return drafts, nil
+}
+
+// Clusters the commits of a repository, sorted by time, into work sessions.
+func (g GitTimeEntryGenerator) Sessions(repository string, commits []GitCommit) []GitWorkSession {
+	gap := g.SessionGap
+
+	if gap <= 0 {
+		gap = 2 * time.Hour
+	}
+
+	var sessions []GitWorkSession
+	// The open session of every mapping, so interleaved work on several branches of the
+	// same repository is tracked separately
+	open := make(map[*GitProjectMapping]int)
+
+	for _, commit := range commits {
+		mapping := g.match(repository, commit.Branch)
+
+		if i, ok := open[mapping]; ok && commit.Time.Sub(sessions[i].End) <= gap {
+			sessions[i].Commits = append(sessions[i].Commits, commit)
+			sessions[i].End = commit.Time
+			continue
+		}
+
+		open[mapping] = len(sessions)
+		sessions = append(sessions, GitWorkSession{
+			Repository: repository,
+			Commits:    []GitCommit{commit},
+			Start:      commit.Time.Add(-g.leadTime()),
+			End:        commit.Time,
+			Mapping:    mapping,
+		})
+	}
+
+	return sessions
+}
+
+func (g GitTimeEntryGenerator) draft(session GitWorkSession) GitTimeEntryDraft {
+	draft := GitTimeEntryDraft{Session: session}
+
+	if session.Mapping == nil {
+		draft.SkipReason = fmt.Sprintf("no mapping for repository %s, branch %s",
+			session.Repository, session.Commits[0].Branch)
+		return draft
+	}
+
+	loc := g.Location
+
+	if loc == nil {
+		loc = time.Local
+	}
+
+	start := session.Start.In(loc)
+	seconds := decimal.NewFromInt(int64(session.End.Sub(session.Start) / time.Second))
+	hours := seconds.Div(decimal.NewFromInt(3600)).Round(2)
+
+	notes := []string{session.Repository}
+
+	for _, commit := range session.Commits {
+		notes = append(notes, "- "+commit.Subject)
+	}
+
+	draft.Request = &CreateTimeEntryViaDurationRequest{
+		ProjectId: session.Mapping.ProjectId,
+		TaskId:    session.Mapping.TaskId,
+		SpentDate: time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, time.UTC),
+		UserId:    g.UserId,
+		Hours:     OptionalDecimal(hours),
+		Notes:     OptionalString(strings.Join(notes, "\n")),
+	}
+
+	if session.Mapping.CommitUrl != "" {
+		// Keyed on the first commit, which stays the same when later commits extend the
+		// session, so upserting the draft again updates the same time entry
+		first := session.Commits[0]
+		draft.Request.ExternalRef = &ExternalReference{
+			Id:        first.Hash,
+			GroupId:   session.Repository,
+			Permalink: fmt.Sprintf(session.Mapping.CommitUrl, first.Hash),
+		}
+	}
+
+	return draft
+}
+
+func (g GitTimeEntryGenerator) match(repository, branch string) *GitProjectMapping {
+	for i, mapping := range g.Mappings {
+		if mapping.Repository != repository {
+			continue
+		}
+
+		if mapping.BranchPattern != nil && !mapping.BranchPattern.MatchString(branch) {
+			continue
+		}
+
+		return &g.Mappings[i]
+	}
+
+	return nil
+}
+
+func (g GitTimeEntryGenerator) leadTime() time.Duration {
+	if g.LeadTime < 0 {
+		return 0
+	}
+
+	if g.LeadTime == 0 {
+		return 30 * time.Minute
+	}
+
+	return g.LeadTime
+}
+
+// Turns the ref reported by git log --source into a branch name.
+func gitBranchName(ref string) string {
+	for _, prefix := range []string{"refs/heads/", "refs/remotes/origin/", "refs/remotes/", "refs/tags/"} {
+		if strings.HasPrefix(ref, prefix) {
+			return strings.TrimPrefix(ref, prefix)
+		}
+	}
+
+	return ref
+}
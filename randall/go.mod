@@ -2,6 +2,8 @@ module github.com/calexa22/randall
 
 go 1.19
 
-require github.com/google/go-querystring v1.1.0
-
-require github.com/shopspring/decimal v1.3.1 // indirect
+require (
+	github.com/google/go-querystring v1.1.0
+	github.com/mattn/go-sqlite3 v1.14.22
+	github.com/shopspring/decimal v1.3.1
+)
@@ -35,16 +35,30 @@ type internalClient struct {
 	accessToken    string
 	userAgentApp   string
 	userAgentEmail string
+	skipValidation bool
+	rounding       RoundingPolicy
 }
 
+// Configures a HarvestClient created by NewClient.
+type ClientOption func(*internalClient)
+
 type multipartData struct {
 	data  map[string]string
 	files map[string]string
 }
 
+// Sends requests without validating them first, leaving it to Harvest to reject invalid
+// requests.
+func WithoutValidation() ClientOption {
+	return func(client *internalClient) {
+		client.skipValidation = true
+	}
+}
+
 // Initializes a new instance of Client. Requests through the Client will have the headers
-// required by the Harvest API with the passed in values
-func NewClient(accountId, accessToken, userAgentApp, userAgentEmail string) *HarvestClient {
+// required by the Harvest API with the passed in values. Request bodies are validated before
+// they are sent unless WithoutValidation is passed, failing with a *ValidationError.
+func NewClient(accountId, accessToken, userAgentApp, userAgentEmail string, opts ...ClientOption) *HarvestClient {
 	internal := &internalClient{
 		httpClient:     &http.Client{},
 		baseUrl:        "https://api.harvestapp.com",
@@ -54,6 +68,10 @@ func NewClient(accountId, accessToken, userAgentApp, userAgentEmail string) *Har
 		userAgentEmail: userAgentApp,
 	}
 
+	for _, opt := range opts {
+		opt(internal)
+	}
+
 	return &HarvestClient{
 		Clients:     newClientsV2(internal),
 		Company:     newCompanyV2(internal),
@@ -171,6 +189,10 @@ func (client *internalClient) getJsonBody(body []interface{}) (*bytes.Buffer, er
 	var b *bytes.Buffer
 
 	if len(body) > 0 && body[0] != nil {
+		if err := client.validateBody(body[0]); err != nil {
+			return nil, err
+		}
+
 		buff, err := json.Marshal(body[0])
 
 		if err != nil {
@@ -233,16 +255,26 @@ func (client *internalClient) readResponse(req *http.Request) (HarvestResponse,
 
 	defer resp.Body.Close()
 
-	var data map[string]interface{}
-
-	err = json.NewDecoder(resp.Body).Decode(&data)
+	body, err := io.ReadAll(resp.Body)
 
 	if err != nil {
 		return HarvestResponse{}, err
 	}
 
+	var data map[string]interface{}
+
+	// DELETE requests respond with an empty body
+	if len(bytes.TrimSpace(body)) > 0 {
+		err = json.Unmarshal(body, &data)
+
+		if err != nil {
+			return HarvestResponse{}, err
+		}
+	}
+
 	return HarvestResponse{
 		StatusCode: resp.StatusCode,
 		Data:       data,
+		body:       body,
 	}, nil
 }
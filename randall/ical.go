@@ -0,0 +1,655 @@
+package randall
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// An event read from an iCalendar (.ics) file. Recurring events are expanded into one
+// CalendarEvent per occurrence by ExpandCalendarEvents.
+type CalendarEvent struct {
+	Uid         string
+	Summary     string
+	Description string
+	Location    string
+	// The email address of the organizer.
+	Organizer string
+	// The email addresses of the attendees.
+	Attendees []string
+	Start     time.Time
+	End       time.Time
+	// Whether the event spans whole days rather than a time range.
+	AllDay    bool
+	Cancelled bool
+	// Why the event could not be read, e.g. an unsupported recurrence rule or timezone.
+	// Such events are skipped when drafting time entries.
+	Err error
+
+	recurrence   *calendarRecurrence
+	exceptions   []time.Time
+	recurrenceId time.Time
+	// The DURATION of the event, resolved into End once DTSTART is known as it may come
+	// later.
+	duration *time.Duration
+	// Whether the event replaces a single occurrence of a recurring event.
+	isOverride bool
+}
+
+// Maps calendar events to a project and task. Every condition that is set must match,
+// a rule without conditions matches every event.
+type CalendarRule struct {
+	// Matches events organized by an address of this domain, e.g. "client.com".
+	OrganizerDomain string
+	// Matches events whose summary matches the expression.
+	TitlePattern *regexp.Regexp
+	// Matches events attended by this address, or by any address of a domain when
+	// written as "@client.com".
+	Attendee  string
+	ProjectId uint
+	TaskId    uint
+}
+
+// Drafts time entries from calendar events.
+type CalendarImport struct {
+	// The rules events are mapped with. The first matching rule wins.
+	Rules []CalendarRule
+	// The Harvest timezone of the user the time entries are drafted for, e.g.
+	// EasternTimeUsCanada. Harvest interprets start and end times in this timezone.
+	Timezone Timezone
+	// The user to draft the time entries for. Defaults to the authenticated user.
+	UserId *uint
+}
+
+// A time entry drafted from a calendar event, to be reviewed before submitting it.
+type CalendarDraft struct {
+	Event CalendarEvent
+	// The drafted time entry. Nil when the event was skipped.
+	Request *CreateTimeEntryViaStartEndRequest
+	// Why no time entry was drafted for the event.
+	SkipReason string
+	// The id of the time entry created by SubmitCalendarDrafts.
+	TimeEntryId uint
+	// The error returned by Harvest when creating the time entry.
+	Err error
+}
+
+type calendarRecurrence struct {
+	freq     string
+	interval int
+	count    int
+	until    time.Time
+	byDay    []time.Weekday
+}
+
+type icalProperty struct {
+	name   string
+	params map[string]string
+	value  string
+}
+
+var icalWeekdays = map[string]time.Weekday{
+	"SU": time.Sunday,
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+}
+
+// Reads the events of an iCalendar file. Times without a timezone ("floating" times) are
+// read in loc. Recurring events are returned once, see ExpandCalendarEvents. Problems with a
+// single event are reported in its Err rather than failing the whole file.
+func ParseICalendar(r io.Reader, loc *time.Location) ([]CalendarEvent, error) {
+	lines, err := unfoldICalendarLines(r)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var events []CalendarEvent
+	var current *CalendarEvent
+	depth := 0
+
+	for i, line := range lines {
+		prop, err := parseICalendarProperty(line)
+
+		if err != nil && current == nil {
+			return nil, fmt.Errorf("line %d: %w", i+1, err)
+		}
+
+		if err != nil {
+			if current.Err == nil {
+				current.Err = fmt.Errorf("line %d: %w", i+1, err)
+			}
+
+			continue
+		}
+
+		switch {
+		case prop.name == "BEGIN" && prop.value == "VEVENT":
+			current = &CalendarEvent{}
+			depth = 0
+			continue
+		case current == nil:
+			continue
+		case prop.name == "BEGIN":
+			// Nested components, e.g. VALARM, are ignored
+			depth++
+			continue
+		case prop.name == "END" && prop.value == "VEVENT":
+			if current.End.IsZero() && current.duration != nil {
+				current.End = current.Start.Add(*current.duration)
+			}
+
+			if current.End.IsZero() {
+				current.End = current.Start
+			}
+
+			events = append(events, *current)
+			current = nil
+			continue
+		case prop.name == "END":
+			depth--
+			continue
+		case depth > 0:
+			continue
+		}
+
+		if err := current.setProperty(prop, loc); err != nil && current.Err == nil {
+			current.Err = fmt.Errorf("%s: %w", prop.name, err)
+		}
+	}
+
+	return events, nil
+}
+
+// Expands recurring events into their occurrences starting within [from, to), replacing
+// occurrences that were rescheduled or excluded. Supports daily, weekly and monthly rules.
+// Events that could not be read are kept if they start within [from, to), unexpanded.
+func ExpandCalendarEvents(events []CalendarEvent, from, to time.Time) []CalendarEvent {
+	overrides := make(map[string]map[time.Time]bool)
+
+	for _, event := range events {
+		if event.isOverride {
+			if overrides[event.Uid] == nil {
+				overrides[event.Uid] = make(map[time.Time]bool)
+			}
+
+			overrides[event.Uid][event.recurrenceId.UTC()] = true
+		}
+	}
+
+	var expanded []CalendarEvent
+
+	for _, event := range events {
+		if event.recurrence == nil {
+			if !event.Start.Before(from) && event.Start.Before(to) {
+				expanded = append(expanded, event)
+			}
+
+			continue
+		}
+
+		duration := event.End.Sub(event.Start)
+		excluded := make(map[time.Time]bool, len(event.exceptions))
+
+		for _, exception := range event.exceptions {
+			excluded[exception.UTC()] = true
+		}
+
+		for _, start := range event.recurrence.occurrences(event.Start, to) {
+			if start.Before(from) || excluded[start.UTC()] || overrides[event.Uid][start.UTC()] {
+				continue
+			}
+
+			occurrence := event
+			occurrence.Start = start
+			occurrence.End = start.Add(duration)
+			occurrence.recurrence = nil
+			expanded = append(expanded, occurrence)
+		}
+	}
+
+	sort.SliceStable(expanded, func(i, j int) bool {
+		return expanded[i].Start.Before(expanded[j].Start)
+	})
+
+	return expanded
+}
+
+// Maps every event to a time entry using the first matching rule. Cancelled, all day,
+// unreadable and unmatched events are skipped, as are events spanning several days in the
+// user's timezone.
+func (ci CalendarImport) Draft(events []CalendarEvent) ([]CalendarDraft, error) {
+	loc, err := ci.Timezone.Location()
+
+	if err != nil {
+		return nil, err
+	}
+
+	drafts := make([]CalendarDraft, len(events))
+
+	for i, event := range events {
+		drafts[i] = ci.draft(event, loc)
+	}
+
+	return drafts, nil
+}
+
+func (ci CalendarImport) draft(event CalendarEvent, loc *time.Location) CalendarDraft {
+	draft := CalendarDraft{Event: event}
+
+	switch {
+	case event.Err != nil:
+		draft.SkipReason = event.Err.Error()
+		return draft
+	case event.Cancelled:
+		draft.SkipReason = "event is cancelled"
+		return draft
+	case event.AllDay:
+		draft.SkipReason = "event lasts all day"
+		return draft
+	case !event.End.After(event.Start):
+		draft.SkipReason = "event has no duration"
+		return draft
+	}
+
+	rule := ci.match(event)
+
+	if rule == nil {
+		draft.SkipReason = "no rule matches the event"
+		return draft
+	}
+
+	periods := SplitAtMidnight(event.Start, event.End, loc)
+
+	if len(periods) > 1 {
+		draft.SkipReason = "event spans several days"
+		return draft
+	}
+
+	started, ended := periods[0].ClockTimes()
+
+	draft.Request = &CreateTimeEntryViaStartEndRequest{
+		ProjectId:   rule.ProjectId,
+		TaskId:      rule.TaskId,
+		SpentDate:   periods[0].SpentDate,
+		UserId:      ci.UserId,
+		StartedTime: OptionalString(started),
+		EndTime:     OptionalString(ended),
+		Notes:       OptionalString(event.Summary),
+	}
+
+	return draft
+}
+
+func (ci CalendarImport) match(event CalendarEvent) *CalendarRule {
+	for i, rule := range ci.Rules {
+		if rule.matches(event) {
+			return &ci.Rules[i]
+		}
+	}
+
+	return nil
+}
+
+func (rule CalendarRule) matches(event CalendarEvent) bool {
+	if rule.OrganizerDomain != "" &&
+		!strings.HasSuffix(strings.ToLower(event.Organizer), "@"+strings.ToLower(rule.OrganizerDomain)) {
+		return false
+	}
+
+	if rule.TitlePattern != nil && !rule.TitlePattern.MatchString(event.Summary) {
+		return false
+	}
+
+	if rule.Attendee != "" {
+		attendee := strings.ToLower(rule.Attendee)
+		found := false
+
+		for _, address := range event.Attendees {
+			address = strings.ToLower(address)
+
+			if address == attendee || (strings.HasPrefix(attendee, "@") && strings.HasSuffix(address, attendee)) {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Creates a time entry for every draft that has a request, recording the created time entry
+// id or the error on each draft. Returns the first error encountered, if any.
+func SubmitCalendarDrafts(api TimeEntriesApi, drafts []CalendarDraft) error {
+	var firstErr error
+
+	for i := range drafts {
+		if drafts[i].Request == nil {
+			continue
+		}
+
+		var created struct {
+			Id uint `json:"id"`
+		}
+
+		resp, err := api.CreateViaStartEnd(*drafts[i].Request)
+
+		if err := DecodeResponse(resp, err, &created); err != nil {
+			drafts[i].Err = err
+
+			if firstErr == nil {
+				firstErr = err
+			}
+
+			continue
+		}
+
+		drafts[i].TimeEntryId = created.Id
+	}
+
+	return firstErr
+}
+
+func (event *CalendarEvent) setProperty(prop icalProperty, loc *time.Location) error {
+	var err error
+
+	switch prop.name {
+	case "UID":
+		event.Uid = prop.value
+	case "SUMMARY":
+		event.Summary = unescapeICalendarText(prop.value)
+	case "DESCRIPTION":
+		event.Description = unescapeICalendarText(prop.value)
+	case "LOCATION":
+		event.Location = unescapeICalendarText(prop.value)
+	case "STATUS":
+		event.Cancelled = strings.EqualFold(prop.value, "CANCELLED")
+	case "ORGANIZER":
+		event.Organizer = calendarAddress(prop.value)
+	case "ATTENDEE":
+		event.Attendees = append(event.Attendees, calendarAddress(prop.value))
+	case "DTSTART":
+		event.Start, event.AllDay, err = parseICalendarTime(prop, loc)
+	case "DTEND":
+		event.End, _, err = parseICalendarTime(prop, loc)
+	case "DURATION":
+		var d time.Duration
+
+		if d, err = parseICalendarDuration(prop.value); err == nil {
+			event.duration = &d
+		}
+	case "RRULE":
+		event.recurrence, err = parseICalendarRecurrence(prop.value, loc)
+	case "EXDATE":
+		for _, value := range strings.Split(prop.value, ",") {
+			var t time.Time
+			t, _, err = parseICalendarTime(icalProperty{name: prop.name, params: prop.params, value: value}, loc)
+
+			if err != nil {
+				break
+			}
+
+			event.exceptions = append(event.exceptions, t)
+		}
+	case "RECURRENCE-ID":
+		event.recurrenceId, _, err = parseICalendarTime(prop, loc)
+		event.isOverride = true
+	}
+
+	return err
+}
+
+// Returns the start times of the occurrences of a recurring event starting before to.
+func (rec calendarRecurrence) occurrences(start, to time.Time) []time.Time {
+	var starts []time.Time
+
+	// Guards against unbounded rules
+	const maxOccurrences = 10000
+
+	emit := func(t time.Time) bool {
+		if !t.Before(to) || (!rec.until.IsZero() && t.After(rec.until)) {
+			return false
+		}
+
+		if rec.count > 0 && len(starts) >= rec.count {
+			return false
+		}
+
+		starts = append(starts, t)
+		return len(starts) < maxOccurrences
+	}
+
+	for period := 0; period <= maxOccurrences; period++ {
+		var candidates []time.Time
+
+		switch rec.freq {
+		case "DAILY":
+			candidates = []time.Time{start.AddDate(0, 0, period*rec.interval)}
+		case "WEEKLY":
+			weekStart := start.AddDate(0, 0, period*rec.interval*7-int(start.Weekday()))
+
+			if len(rec.byDay) == 0 {
+				candidates = []time.Time{start.AddDate(0, 0, period*rec.interval*7)}
+			}
+
+			for _, day := range rec.byDay {
+				candidate := weekStart.AddDate(0, 0, int(day))
+
+				if !candidate.Before(start) {
+					candidates = append(candidates, candidate)
+				}
+			}
+		case "MONTHLY":
+			candidate := start.AddDate(0, period*rec.interval, 0)
+
+			// Months without the start's day are skipped, as required by RFC 5545
+			if candidate.Day() == start.Day() {
+				candidates = []time.Time{candidate}
+			}
+		default:
+			return []time.Time{start}
+		}
+
+		for _, candidate := range candidates {
+			if !emit(candidate) {
+				return starts
+			}
+		}
+	}
+
+	return starts
+}
+
+func unfoldICalendarLines(r io.Reader) ([]string, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	var lines []string
+
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+
+	return lines, scanner.Err()
+}
+
+func parseICalendarProperty(line string) (icalProperty, error) {
+	inQuotes := false
+	colon := -1
+
+	for i, c := range line {
+		if c == '"' {
+			inQuotes = !inQuotes
+		} else if c == ':' && !inQuotes {
+			colon = i
+			break
+		}
+	}
+
+	if colon < 0 {
+		return icalProperty{}, fmt.Errorf("malformed content line %q", line)
+	}
+
+	parts := strings.Split(line[:colon], ";")
+	prop := icalProperty{
+		name:   strings.ToUpper(parts[0]),
+		params: make(map[string]string, len(parts)-1),
+		value:  line[colon+1:],
+	}
+
+	for _, param := range parts[1:] {
+		key, value, _ := strings.Cut(param, "=")
+		prop.params[strings.ToUpper(key)] = strings.Trim(value, `"`)
+	}
+
+	return prop, nil
+}
+
+// Parses a DATE or DATE-TIME value, returning whether it was a DATE.
+func parseICalendarTime(prop icalProperty, loc *time.Location) (time.Time, bool, error) {
+	value := strings.TrimSpace(prop.value)
+
+	if prop.params["VALUE"] == "DATE" || len(value) == 8 {
+		t, err := time.ParseInLocation("20060102", value, loc)
+		return t, true, err
+	}
+
+	if strings.HasSuffix(value, "Z") {
+		t, err := time.Parse("20060102T150405Z", value)
+		return t, false, err
+	}
+
+	if tzid, ok := prop.params["TZID"]; ok {
+		tzLoc, err := LoadHarvestLocation(tzid)
+
+		if err != nil {
+			return time.Time{}, false, err
+		}
+
+		loc = tzLoc
+	}
+
+	t, err := time.ParseInLocation("20060102T150405", value, loc)
+	return t, false, err
+}
+
+var icalDurationPattern = regexp.MustCompile(`^([+-])?P(?:(\d+)W)?(?:(\d+)D)?(?:T(?:(\d+)H)?(?:(\d+)M)?(?:(\d+)S)?)?$`)
+
+func parseICalendarDuration(value string) (time.Duration, error) {
+	m := icalDurationPattern.FindStringSubmatch(strings.TrimSpace(value))
+
+	if m == nil {
+		return 0, fmt.Errorf("malformed duration %q", value)
+	}
+
+	units := []time.Duration{7 * 24 * time.Hour, 24 * time.Hour, time.Hour, time.Minute, time.Second}
+	var d time.Duration
+
+	for i, unit := range units {
+		if m[i+2] == "" {
+			continue
+		}
+
+		n, _ := strconv.Atoi(m[i+2])
+		d += time.Duration(n) * unit
+	}
+
+	if m[1] == "-" {
+		d = -d
+	}
+
+	return d, nil
+}
+
+func parseICalendarRecurrence(value string, loc *time.Location) (*calendarRecurrence, error) {
+	rec := &calendarRecurrence{interval: 1}
+
+	for _, part := range strings.Split(value, ";") {
+		key, v, _ := strings.Cut(part, "=")
+		var err error
+
+		switch strings.ToUpper(key) {
+		case "FREQ":
+			rec.freq = strings.ToUpper(v)
+		case "INTERVAL":
+			rec.interval, err = strconv.Atoi(v)
+		case "COUNT":
+			rec.count, err = strconv.Atoi(v)
+		case "UNTIL":
+			rec.until, _, err = parseICalendarTime(icalProperty{value: v}, loc)
+		case "BYDAY":
+			for _, day := range strings.Split(v, ",") {
+				// Ordinal prefixes such as 1MO only apply to monthly rules, which are not supported
+				weekday, ok := icalWeekdays[strings.ToUpper(day)]
+
+				if !ok {
+					return nil, fmt.Errorf("unsupported BYDAY value %q", day)
+				}
+
+				rec.byDay = append(rec.byDay, weekday)
+			}
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("malformed RRULE %s: %w", key, err)
+		}
+	}
+
+	switch rec.freq {
+	case "DAILY", "WEEKLY", "MONTHLY":
+	default:
+		return nil, fmt.Errorf("unsupported recurrence frequency %q", rec.freq)
+	}
+
+	if rec.interval < 1 {
+		return nil, errors.New("recurrence interval must be positive")
+	}
+
+	if rec.freq != "WEEKLY" && len(rec.byDay) > 0 {
+		return nil, fmt.Errorf("BYDAY is only supported for weekly recurrences")
+	}
+
+	sort.Slice(rec.byDay, func(i, j int) bool {
+		return rec.byDay[i] < rec.byDay[j]
+	})
+
+	return rec, nil
+}
+
+func calendarAddress(value string) string {
+	value = strings.TrimSpace(value)
+
+	if strings.HasPrefix(strings.ToLower(value), "mailto:") {
+		value = value[len("mailto:"):]
+	}
+
+	return value
+}
+
+func unescapeICalendarText(value string) string {
+	replacer := strings.NewReplacer(`\n`, "\n", `\N`, "\n", `\,`, ",", `\;`, ";", `\\`, `\`)
+	return replacer.Replace(value)
+}
@@ -0,0 +1,124 @@
+package randall
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseICalendarRecurrence(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    calendarRecurrence
+		wantErr bool
+	}{
+		{value: "FREQ=DAILY", want: calendarRecurrence{freq: "DAILY", interval: 1}},
+		{value: "freq=weekly;byday=tu", want: calendarRecurrence{freq: "WEEKLY", interval: 1, byDay: []time.Weekday{time.Tuesday}}},
+		{
+			value: "FREQ=WEEKLY;INTERVAL=2;BYDAY=FR,MO",
+			want:  calendarRecurrence{freq: "WEEKLY", interval: 2, byDay: []time.Weekday{time.Monday, time.Friday}},
+		},
+		{value: "FREQ=MONTHLY;COUNT=3", want: calendarRecurrence{freq: "MONTHLY", interval: 1, count: 3}},
+		{
+			value: "FREQ=DAILY;UNTIL=20240105T000000Z",
+			want:  calendarRecurrence{freq: "DAILY", interval: 1, until: time.Date(2024, 1, 5, 0, 0, 0, 0, time.UTC)},
+		},
+		{value: "", wantErr: true},
+		{value: "FREQ=YEARLY", wantErr: true},
+		{value: "FREQ=MONTHLY;BYDAY=2TU", wantErr: true},
+		{value: "FREQ=WEEKLY;BYDAY=XX", wantErr: true},
+		{value: "FREQ=DAILY;BYDAY=MO", wantErr: true},
+		{value: "FREQ=DAILY;INTERVAL=0", wantErr: true},
+		{value: "FREQ=DAILY;INTERVAL=x", wantErr: true},
+		{value: "FREQ=DAILY;COUNT=x", wantErr: true},
+		{value: "FREQ=DAILY;UNTIL=tomorrow", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := parseICalendarRecurrence(tt.value, time.UTC)
+
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got %+v", *got)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(*got, tt.want) {
+				t.Errorf("got %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCalendarRecurrenceOccurrences(t *testing.T) {
+	day := func(month time.Month, d int) time.Time {
+		return time.Date(2024, month, d, 10, 0, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		rule  string
+		start time.Time
+		to    time.Time
+		want  []time.Time
+	}{
+		{"FREQ=DAILY;COUNT=3", day(1, 1), day(12, 31), []time.Time{day(1, 1), day(1, 2), day(1, 3)}},
+		{"FREQ=DAILY;UNTIL=20240103T100000Z", day(1, 1), day(12, 31), []time.Time{day(1, 1), day(1, 2), day(1, 3)}},
+		{"FREQ=DAILY", day(1, 1), day(1, 3), []time.Time{day(1, 1), day(1, 2)}},
+		{"FREQ=WEEKLY;BYDAY=MO,WE", day(1, 1), day(1, 10), []time.Time{day(1, 1), day(1, 3), day(1, 8)}},
+		{"FREQ=WEEKLY;BYDAY=MO,WE", day(1, 3), day(1, 11), []time.Time{day(1, 3), day(1, 8), day(1, 10)}},
+		{"FREQ=WEEKLY;INTERVAL=2", day(1, 1), day(2, 1), []time.Time{day(1, 1), day(1, 15), day(1, 29)}},
+		{"FREQ=MONTHLY;COUNT=3", day(1, 31), day(12, 31), []time.Time{day(1, 31), day(3, 31), day(5, 31)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.rule, func(t *testing.T) {
+			rec, err := parseICalendarRecurrence(tt.rule, time.UTC)
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got := rec.occurrences(tt.start, tt.to); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseICalendarReportsUnreadableEvents(t *testing.T) {
+	ics := "BEGIN:VCALENDAR\r\n" +
+		"BEGIN:VEVENT\r\nUID:yearly\r\nDTSTART:20240102T100000Z\r\nRRULE:FREQ=YEARLY\r\nEND:VEVENT\r\n" +
+		"BEGIN:VEVENT\r\nUID:windows\r\nDTSTART;TZID=W. Europe Standard Time:20240102T100000\r\nEND:VEVENT\r\n" +
+		"BEGIN:VEVENT\r\nUID:ok\r\nDTSTART:20240102T100000Z\r\nDURATION:PT1H\r\nEND:VEVENT\r\n" +
+		"END:VCALENDAR\r\n"
+
+	events, err := ParseICalendar(strings.NewReader(ics), time.UTC)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wantErr := map[string]bool{"yearly": true, "windows": true, "ok": false}
+
+	if len(events) != len(wantErr) {
+		t.Fatalf("got %d events, want %d", len(events), len(wantErr))
+	}
+
+	for _, event := range events {
+		if (event.Err != nil) != wantErr[event.Uid] {
+			t.Errorf("event %s: Err = %v", event.Uid, event.Err)
+		}
+	}
+
+	if want := time.Date(2024, 1, 2, 11, 0, 0, 0, time.UTC); !events[2].End.Equal(want) {
+		t.Errorf("End = %s, want %s", events[2].End, want)
+	}
+}
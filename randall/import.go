@@ -0,0 +1,597 @@
+package randall
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// A row of a time entry import, as read by ParseTimeEntryCsv.
+type TimeEntryImportRow struct {
+	// The line of the row in the source file, for reporting.
+	Line      int
+	SpentDate string
+	UserEmail string
+	// The name of the project's client. Optional, narrows down the project lookup.
+	Client string
+	// The code or name of the project.
+	Project string
+	// The name of the task, or its ID as Harvest tasks have no code.
+	Task string
+	// Either Hours or both StartedTime and EndedTime must be set.
+	Hours       string
+	StartedTime string
+	EndedTime   string
+	Notes       string
+}
+
+// The outcome of importing a single row.
+type TimeEntryImportResult struct {
+	Row TimeEntryImportRow
+	// The problems found while validating the row. Rows with problems are never created.
+	Problems []string
+	// The request the row resolved to, set for valid rows. Exactly one of the two is set.
+	DurationRequest *CreateTimeEntryViaDurationRequest
+	StartEndRequest *CreateTimeEntryViaStartEndRequest
+	// The id of the created time entry. Zero when nothing was created.
+	TimeEntryId uint
+	// The error returned by Harvest when creating the time entry.
+	Err error
+}
+
+type TimeEntryImportReport struct {
+	DryRun  bool
+	Results []TimeEntryImportResult
+}
+
+// Imports time entries from spreadsheet rows, resolving user emails and project and task
+// names to Harvest IDs. Every row is validated before any time entry is created.
+type TimeEntryImporter struct {
+	// Validates and resolves the rows without creating any time entries.
+	DryRun bool
+	// The number of time entries created in parallel. Defaults to 1.
+	Concurrency int
+	// The minimum time between two create requests across all workers, to stay within
+	// Harvest's rate limits. Defaults to 150ms, Harvest allows 100 requests per 15 seconds.
+	RequestInterval time.Duration
+
+	client *HarvestClient
+}
+
+// The Harvest data rows are resolved against.
+type importLookup struct {
+	usersByEmail map[string]User
+	projects     []Project
+	tasks        map[uint][]TaskAssignment
+	assigned     map[uint]map[uint]bool
+}
+
+var ErrInvalidImport = errors.New("the import contains invalid rows, nothing was created")
+
+var timeEntryCsvHeaders = map[string]string{
+	"date":         "date",
+	"spent_date":   "date",
+	"email":        "user_email",
+	"user_email":   "user_email",
+	"client":       "client",
+	"project":      "project",
+	"project_code": "project",
+	"task":         "task",
+	"task_id":      "task",
+	"hours":        "hours",
+	"start":        "started_time",
+	"started_time": "started_time",
+	"end":          "ended_time",
+	"ended_time":   "ended_time",
+	"notes":        "notes",
+}
+
+// Reads the time entry rows of a CSV file. The first line must be a header naming the
+// columns: date, user_email, client, project, task, hours, started_time, ended_time and notes.
+// The columns may appear in any order and unused columns may be omitted.
+func ParseTimeEntryCsv(r io.Reader) ([]TimeEntryImportRow, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+
+	header, err := cr.Read()
+
+	if err != nil {
+		return nil, err
+	}
+
+	fields := make([]string, len(header))
+
+	for i, name := range header {
+		field, ok := timeEntryCsvHeaders[strings.ToLower(strings.TrimSpace(name))]
+
+		if !ok {
+			return nil, fmt.Errorf("unknown column %q", name)
+		}
+
+		fields[i] = field
+	}
+
+	var rows []TimeEntryImportRow
+
+	for {
+		record, err := cr.Read()
+
+		if err == io.EOF {
+			return rows, nil
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		line, _ := cr.FieldPos(0)
+		row := TimeEntryImportRow{Line: line}
+
+		for i, value := range record {
+			if i >= len(fields) {
+				break
+			}
+
+			value = strings.TrimSpace(value)
+
+			switch fields[i] {
+			case "date":
+				row.SpentDate = value
+			case "user_email":
+				row.UserEmail = value
+			case "client":
+				row.Client = value
+			case "project":
+				row.Project = value
+			case "task":
+				row.Task = value
+			case "hours":
+				row.Hours = value
+			case "started_time":
+				row.StartedTime = value
+			case "ended_time":
+				row.EndedTime = value
+			case "notes":
+				row.Notes = value
+			}
+		}
+
+		rows = append(rows, row)
+	}
+}
+
+// Initializes a new TimeEntryImporter that creates time entries through client.
+func NewTimeEntryImporter(client *HarvestClient) *TimeEntryImporter {
+	return &TimeEntryImporter{
+		Concurrency:     1,
+		RequestInterval: 150 * time.Millisecond,
+		client:          client,
+	}
+}
+
+// Validates every row and, unless the importer is a dry run, creates a time entry for each.
+// If any row is invalid nothing is created and ErrInvalidImport is returned along with the
+// report describing the problems of every row.
+func (imp *TimeEntryImporter) Import(rows []TimeEntryImportRow) (TimeEntryImportReport, error) {
+	report := TimeEntryImportReport{
+		DryRun:  imp.DryRun,
+		Results: make([]TimeEntryImportResult, len(rows)),
+	}
+
+	lookup, err := imp.loadLookup()
+
+	if err != nil {
+		return report, err
+	}
+
+	invalid := 0
+
+	for i, row := range rows {
+		report.Results[i] = lookup.resolve(row)
+
+		if len(report.Results[i].Problems) > 0 {
+			invalid++
+		}
+	}
+
+	if invalid > 0 {
+		return report, ErrInvalidImport
+	}
+
+	if imp.DryRun {
+		return report, nil
+	}
+
+	imp.create(report.Results)
+
+	for _, result := range report.Results {
+		if result.Err != nil {
+			return report, fmt.Errorf("failed to create some time entries: %w", result.Err)
+		}
+	}
+
+	return report, nil
+}
+
+// Creates the time entries of the results with the importer's concurrency, throttled by
+// its request interval.
+func (imp *TimeEntryImporter) create(results []TimeEntryImportResult) {
+	workers := imp.Concurrency
+
+	if workers < 1 {
+		workers = 1
+	}
+
+	interval := imp.RequestInterval
+
+	if interval <= 0 {
+		interval = time.Nanosecond
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for i := range indexes {
+				<-ticker.C
+				results[i].TimeEntryId, results[i].Err = imp.createOne(results[i])
+			}
+		}()
+	}
+
+	for i := range results {
+		indexes <- i
+	}
+
+	close(indexes)
+	wg.Wait()
+}
+
+func (imp *TimeEntryImporter) createOne(result TimeEntryImportResult) (uint, error) {
+	var created struct {
+		Id uint `json:"id"`
+	}
+
+	var resp HarvestResponse
+	var err error
+
+	if result.DurationRequest != nil {
+		resp, err = imp.client.TimeEntries.CreateViaDuration(*result.DurationRequest)
+	} else {
+		resp, err = imp.client.TimeEntries.CreateViaStartEnd(*result.StartEndRequest)
+	}
+
+	if err := DecodeResponse(resp, err, &created); err != nil {
+		return 0, err
+	}
+
+	return created.Id, nil
+}
+
+func (imp *TimeEntryImporter) loadLookup() (importLookup, error) {
+	active := HarvestCollectionParams{
+		IsActive: OptionalBool(true),
+		PerPage:  OptionalInt(2000),
+	}
+
+	users, err := imp.client.Users.AllUsersPages(active)
+
+	if err != nil {
+		return importLookup{}, err
+	}
+
+	projects, err := imp.client.Projects.GetAllPages(active)
+
+	if err != nil {
+		return importLookup{}, err
+	}
+
+	taskAssignments, err := imp.client.Projects.GetAllTaskAssignmentPages(active)
+
+	if err != nil {
+		return importLookup{}, err
+	}
+
+	userAssignments, err := imp.client.Projects.GetAllUserAssignmentPages(active)
+
+	if err != nil {
+		return importLookup{}, err
+	}
+
+	lookup := importLookup{
+		usersByEmail: make(map[string]User, len(users)),
+		projects:     projects,
+		tasks:        make(map[uint][]TaskAssignment),
+		assigned:     make(map[uint]map[uint]bool),
+	}
+
+	for _, user := range users {
+		lookup.usersByEmail[strings.ToLower(user.Email)] = user
+	}
+
+	for _, assignment := range taskAssignments {
+		lookup.tasks[assignment.Project.Id] = append(lookup.tasks[assignment.Project.Id], assignment)
+	}
+
+	for _, assignment := range userAssignments {
+		if lookup.assigned[assignment.Project.Id] == nil {
+			lookup.assigned[assignment.Project.Id] = make(map[uint]bool)
+		}
+
+		lookup.assigned[assignment.Project.Id][assignment.User.Id] = true
+	}
+
+	return lookup, nil
+}
+
+// Validates the row and resolves it to a create request.
+func (l importLookup) resolve(row TimeEntryImportRow) TimeEntryImportResult {
+	result := TimeEntryImportResult{Row: row}
+	problem := func(format string, args ...interface{}) {
+		result.Problems = append(result.Problems, fmt.Sprintf(format, args...))
+	}
+
+	spentDate, err := time.Parse("2006-01-02", row.SpentDate)
+
+	if err != nil {
+		problem("date %q is not in YYYY-MM-DD format", row.SpentDate)
+	}
+
+	user, userFound := l.usersByEmail[strings.ToLower(row.UserEmail)]
+
+	if !userFound {
+		problem("no active user with email %q", row.UserEmail)
+	}
+
+	project, err := l.findProject(row.Client, row.Project)
+
+	if err != nil {
+		problem("%s", err)
+	}
+
+	var task *TaskAssignment
+
+	if project != nil {
+		task, err = l.findTask(project.Id, row.Task)
+
+		if err != nil {
+			problem("%s", err)
+		}
+
+		if userFound && !l.assigned[project.Id][user.Id] {
+			problem("user %s is not assigned to project %q", row.UserEmail, project.Name)
+		}
+	}
+
+	var hours decimal.Decimal
+	var started, ended string
+	hasHours := row.Hours != ""
+	hasStartEnd := row.StartedTime != "" || row.EndedTime != ""
+
+	switch {
+	case hasHours && hasStartEnd:
+		problem("either hours or start and end time must be set, not both")
+	case hasHours:
+		hours, err = decimal.NewFromString(row.Hours)
+
+		if err != nil || !hours.IsPositive() || hours.GreaterThan(decimal.NewFromInt(24)) {
+			problem("hours %q must be a number greater than 0 and at most 24", row.Hours)
+		}
+	case hasStartEnd:
+		started, ended, err = normalizeStartEnd(row.StartedTime, row.EndedTime)
+
+		if err != nil {
+			problem("%s", err)
+		}
+	default:
+		problem("either hours or start and end time must be set")
+	}
+
+	if len(result.Problems) > 0 {
+		return result
+	}
+
+	var notes *string
+
+	if row.Notes != "" {
+		notes = OptionalString(row.Notes)
+	}
+
+	if hasHours {
+		result.DurationRequest = &CreateTimeEntryViaDurationRequest{
+			ProjectId: project.Id,
+			TaskId:    task.Task.Id,
+			SpentDate: spentDate,
+			UserId:    OptionalUInt(user.Id),
+			Hours:     OptionalDecimal(hours),
+			Notes:     notes,
+		}
+	} else {
+		result.StartEndRequest = &CreateTimeEntryViaStartEndRequest{
+			ProjectId:   project.Id,
+			TaskId:      task.Task.Id,
+			SpentDate:   spentDate,
+			UserId:      OptionalUInt(user.Id),
+			StartedTime: OptionalString(started),
+			EndTime:     OptionalString(ended),
+			Notes:       notes,
+		}
+	}
+
+	return result
+}
+
+// Finds the active project with the given code, or failing that the given name. When
+// client is set only that client's projects are considered.
+func (l importLookup) findProject(client, codeOrName string) (*Project, error) {
+	if codeOrName == "" {
+		return nil, errors.New("project is required")
+	}
+
+	var byCode, byName []*Project
+
+	for i := range l.projects {
+		project := &l.projects[i]
+
+		if client != "" && !strings.EqualFold(project.Client.Name, client) {
+			continue
+		}
+
+		if project.Code != "" && strings.EqualFold(project.Code, codeOrName) {
+			byCode = append(byCode, project)
+		}
+
+		if strings.EqualFold(project.Name, codeOrName) {
+			byName = append(byName, project)
+		}
+	}
+
+	matches := byCode
+
+	if len(matches) == 0 {
+		matches = byName
+	}
+
+	switch len(matches) {
+	case 0:
+		if client != "" {
+			return nil, fmt.Errorf("no active project %q for client %q", codeOrName, client)
+		}
+
+		return nil, fmt.Errorf("no active project %q", codeOrName)
+	case 1:
+		return matches[0], nil
+	default:
+		return nil, fmt.Errorf("project %q is ambiguous, set the client or use the project code", codeOrName)
+	}
+}
+
+// Finds the task assigned to the project with the given name, or failing that the given ID.
+func (l importLookup) findTask(projectId uint, idOrName string) (*TaskAssignment, error) {
+	if idOrName == "" {
+		return nil, errors.New("task is required")
+	}
+
+	for i, assignment := range l.tasks[projectId] {
+		if strings.EqualFold(assignment.Task.Name, idOrName) {
+			return &l.tasks[projectId][i], nil
+		}
+	}
+
+	if id, err := strconv.ParseUint(idOrName, 10, 0); err == nil {
+		for i, assignment := range l.tasks[projectId] {
+			if assignment.Task.Id == uint(id) {
+				return &l.tasks[projectId][i], nil
+			}
+		}
+	}
+
+	return nil, fmt.Errorf("task %q is not assigned to the project", idOrName)
+}
+
+// Parses the start and end times of a row, accepting 12 and 24 hour clock formats, and
+// returns them in the format Harvest expects.
+func normalizeStartEnd(startedTime, endedTime string) (string, string, error) {
+	start, err := parseClockTime(startedTime)
+
+	if err != nil {
+		return "", "", err
+	}
+
+	end, err := parseClockTime(endedTime)
+
+	if err != nil {
+		return "", "", err
+	}
+
+	if !end.After(start) {
+		return "", "", fmt.Errorf("end time %q must be after start time %q", endedTime, startedTime)
+	}
+
+	return formatClockTime(start), formatClockTime(end), nil
+}
+
+var clockTimeLayouts = []string{"3:04pm", "3pm", "15:04", "3:04 pm"}
+
+// Parses a time of day such as "8:00am", "8am" or "17:30".
+func parseClockTime(value string) (time.Time, error) {
+	normalized := strings.ToLower(strings.TrimSpace(value))
+
+	for _, layout := range clockTimeLayouts {
+		if t, err := time.Parse(layout, normalized); err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("time %q is not a valid time of day, e.g. 8:00am or 17:30", value)
+}
+
+// Formats a time of day the way Harvest expects, e.g. "8:00am".
+func formatClockTime(t time.Time) string {
+	return t.Format("3:04pm")
+}
+
+// Returns true if every row is valid.
+func (r TimeEntryImportReport) Valid() bool {
+	for _, result := range r.Results {
+		if len(result.Problems) > 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Writes the outcome of every row as CSV.
+func (r TimeEntryImportReport) WriteCsv(w io.Writer) error {
+	cw := csv.NewWriter(w)
+
+	if err := cw.Write([]string{"line", "status", "time_entry_id", "message"}); err != nil {
+		return err
+	}
+
+	for _, result := range r.Results {
+		status, message := result.status()
+		id := ""
+
+		if result.TimeEntryId != 0 {
+			id = strconv.FormatUint(uint64(result.TimeEntryId), 10)
+		}
+
+		if err := cw.Write([]string{strconv.Itoa(result.Row.Line), status, id, message}); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+func (result TimeEntryImportResult) status() (string, string) {
+	switch {
+	case len(result.Problems) > 0:
+		return "invalid", strings.Join(result.Problems, "; ")
+	case result.Err != nil:
+		return "failed", result.Err.Error()
+	case result.TimeEntryId != 0:
+		return "created", ""
+	default:
+		return "valid", ""
+	}
+}
@@ -0,0 +1,380 @@
+package randall
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Returned by InvoiceDrafter.CreateInvoice when there is no uninvoiced work to import.
+var ErrNothingToInvoice = errors.New("there is no uninvoiced work to invoice")
+
+// The unbilled, billable time entries and expenses of a client within a period.
+type UninvoicedWork struct {
+	ClientId uint
+	From     time.Time
+	To       time.Time
+	// The projects with uninvoiced work, sorted by name.
+	Projects    []ProjectRef
+	TimeEntries []TimeEntry
+	Expenses    []Expense
+	// The hourly rate every time entry is invoiced at, by time entry id.
+	Rates map[uint]decimal.Decimal
+}
+
+// The summary types used to import uninvoiced work into an invoice.
+type InvoiceSummary struct {
+	// One of the InvoiceTimeSummary constants. Defaults to InvoiceTimeSummaryByProject.
+	Time SummaryType
+	// One of the InvoiceExpenseSummary constants. Defaults to InvoiceExpenseSummaryByProject.
+	Expenses       SummaryType
+	AttachReceipts bool
+}
+
+// A line item Harvest is expected to create when importing uninvoiced work.
+type InvoiceDraftLineItem struct {
+	Project     ProjectRef
+	Kind        LineItemKind
+	Description string
+	Quantity    decimal.Decimal
+	UnitPrice   decimal.Decimal
+	Amount      decimal.Decimal
+	// The time entries or expenses summarized by the line item.
+	TimeEntryIds []uint
+	ExpenseIds   []uint
+}
+
+// Gathers uninvoiced work and creates invoices from it.
+type InvoiceDrafter struct {
+	client *HarvestClient
+}
+
+func NewInvoiceDrafter(client *HarvestClient) *InvoiceDrafter {
+	return &InvoiceDrafter{client: client}
+}
+
+// Gathers the billable time entries and expenses of a client spent within [from, to] that
+// were not invoiced yet. Time entries without a billable rate are invoiced at the rate of
+// their project, task assignment or user assignment, following the project's bill_by mode.
+func (d *InvoiceDrafter) Uninvoiced(clientId uint, from, to time.Time) (UninvoicedWork, error) {
+	work := UninvoicedWork{ClientId: clientId, From: from, To: to, Rates: make(map[uint]decimal.Decimal)}
+
+	entries, err := d.client.TimeEntries.GetAllPages(GetTimeEntriesParams{
+		ClientId: int(clientId),
+		IsBilled: OptionalBool(false),
+		FromDate: OptionalTime(from),
+		ToDate:   OptionalTime(to),
+	})
+
+	if err != nil {
+		return work, err
+	}
+
+	expenses, err := d.client.Expenses.GetAllPages(HarvestCollectionParams{
+		ClientId: OptionalUInt(clientId),
+		IsBilled: OptionalBool(false),
+		From:     from,
+		To:       to,
+	})
+
+	if err != nil {
+		return work, err
+	}
+
+	projects := make(map[uint]ProjectRef)
+
+	for _, e := range entries {
+		if e.Billable && !e.IsBilled && !e.IsRunning {
+			work.TimeEntries = append(work.TimeEntries, e)
+			projects[e.Project.Id] = e.Project
+		}
+	}
+
+	for _, e := range expenses {
+		if e.Billable && !e.IsBilled {
+			work.Expenses = append(work.Expenses, e)
+			projects[e.Project.Id] = e.Project
+		}
+	}
+
+	for _, p := range projects {
+		work.Projects = append(work.Projects, p)
+	}
+
+	sort.Slice(work.Projects, func(i, j int) bool {
+		return work.Projects[i].Name < work.Projects[j].Name
+	})
+
+	rates := newInvoiceRateResolver(d.client)
+
+	for _, e := range work.TimeEntries {
+		if e.BillableRate != nil {
+			work.Rates[e.Id] = *e.BillableRate
+			continue
+		}
+
+		rate, err := rates.rate(e)
+
+		if err != nil {
+			return work, err
+		}
+
+		work.Rates[e.Id] = rate
+	}
+
+	return work, nil
+}
+
+// Returns the total amount of the uninvoiced work.
+func (w UninvoicedWork) Amount() decimal.Decimal {
+	amount := decimal.Zero
+
+	for _, e := range w.TimeEntries {
+		amount = amount.Add(e.RoundedHours.Mul(w.Rates[e.Id]))
+	}
+
+	for _, e := range w.Expenses {
+		amount = amount.Add(e.TotalCost)
+	}
+
+	return amount.Round(2)
+}
+
+// Previews the line items Harvest creates when importing the work with the given summary
+// types. Descriptions approximate the ones generated by Harvest.
+func (w UninvoicedWork) Preview(summary InvoiceSummary) ([]InvoiceDraftLineItem, error) {
+	summary = summary.withDefaults()
+
+	var items []InvoiceDraftLineItem
+	index := make(map[string]int)
+
+	add := func(key string, item InvoiceDraftLineItem) *InvoiceDraftLineItem {
+		if i, ok := index[key]; ok {
+			return &items[i]
+		}
+
+		index[key] = len(items)
+		items = append(items, item)
+		return &items[len(items)-1]
+	}
+
+	for _, e := range w.TimeEntries {
+		rate := w.Rates[e.Id]
+		project := projectDescription(e.Project)
+		// Harvest creates separate line items for work invoiced at different rates
+		key := fmt.Sprintf("%d|%s", e.Project.Id, rate.String())
+		description := project
+
+		switch summary.Time {
+		case InvoiceTimeSummaryByProject:
+		case InvoiceTimeSummaryByTask:
+			key += fmt.Sprintf("|%d", e.Task.Id)
+			description = project + ": " + e.Task.Name
+		case InvoiceTimeSummaryByPeople:
+			key += fmt.Sprintf("|%d", e.User.Id)
+			description = project + ": " + e.User.Name
+		case InvoiceTimeSummaryDetailed:
+			key = fmt.Sprintf("entry|%d", e.Id)
+			description = fmt.Sprintf("%s: %s: %s (%s)", project, e.Task.Name, e.Notes, e.SpentDate.Format("01/02/2006"))
+		default:
+			return nil, fmt.Errorf("unknown time summary type %q", summary.Time)
+		}
+
+		item := add(key, InvoiceDraftLineItem{Project: e.Project, Kind: LineItemKindService, Description: description, UnitPrice: rate})
+		item.Quantity = item.Quantity.Add(e.RoundedHours)
+		item.TimeEntryIds = append(item.TimeEntryIds, e.Id)
+	}
+
+	for _, e := range w.Expenses {
+		project := projectDescription(e.Project)
+		key := fmt.Sprintf("expense|%d", e.Project.Id)
+		description := project + ": Expenses"
+
+		switch summary.Expenses {
+		case InvoiceExpenseSummaryByProject:
+		case InvoiceExpenseSummaryByCategory:
+			key += fmt.Sprintf("|%d", e.ExpenseCategory.Id)
+			description = project + ": " + e.ExpenseCategory.Name
+		case InvoiceExpenseSummaryByPeople:
+			key += fmt.Sprintf("|%d", e.User.Id)
+			description = project + ": " + e.User.Name
+		case InvoiceExpenseSummaryDetailed:
+			key = fmt.Sprintf("expense|entry|%d", e.Id)
+			description = fmt.Sprintf("%s: %s: %s (%s)", project, e.ExpenseCategory.Name, e.Notes, e.SpentDate.Format("01/02/2006"))
+		default:
+			return nil, fmt.Errorf("unknown expense summary type %q", summary.Expenses)
+		}
+
+		item := add(key, InvoiceDraftLineItem{Project: e.Project, Kind: LineItemKindProduct, Description: description, Quantity: decimal.NewFromInt(1)})
+		item.UnitPrice = item.UnitPrice.Add(e.TotalCost)
+		item.ExpenseIds = append(item.ExpenseIds, e.Id)
+	}
+
+	for i := range items {
+		items[i].Description = strings.TrimSpace(items[i].Description)
+		items[i].Amount = items[i].Quantity.Mul(items[i].UnitPrice).Round(2)
+	}
+
+	return items, nil
+}
+
+// Creates an invoice for the client of the work that imports its time entries and
+// expenses with the given summary types. The client and line item import of req are
+// overwritten, any other field is sent as is.
+func (d *InvoiceDrafter) CreateInvoice(work UninvoicedWork, summary InvoiceSummary, req CreateInvoiceFromTrackedTimeAndExpenseRequest) (Invoice, error) {
+	if len(work.TimeEntries) == 0 && len(work.Expenses) == 0 {
+		return Invoice{}, ErrNothingToInvoice
+	}
+
+	summary = summary.withDefaults()
+	from, to := work.From, work.To
+	lineItems := &CreateLineItemsImportRequest{}
+
+	for _, p := range work.Projects {
+		lineItems.ProjectIds = append(lineItems.ProjectIds, p.Id)
+	}
+
+	if len(work.TimeEntries) > 0 {
+		lineItems.Time = &TimeImport{SummaryType: summary.Time, From: &from, To: &to}
+	}
+
+	if len(work.Expenses) > 0 {
+		lineItems.Expenses = &ExpensesImport{
+			SummaryType:    summary.Expenses,
+			From:           &from,
+			To:             &to,
+			AttachReceipts: OptionalBool(summary.AttachReceipts),
+		}
+	}
+
+	req.ClientId = work.ClientId
+	req.LineItemsImport = lineItems
+
+	var invoice Invoice
+	resp, err := d.client.Invoices.CreateFromTrackedTimeAndExpenses(req)
+
+	if err := DecodeResponse(resp, err, &invoice); err != nil {
+		return Invoice{}, err
+	}
+
+	return invoice, nil
+}
+
+func (s InvoiceSummary) withDefaults() InvoiceSummary {
+	if s.Time == "" {
+		s.Time = InvoiceTimeSummaryByProject
+	}
+
+	if s.Expenses == "" {
+		s.Expenses = InvoiceExpenseSummaryByProject
+	}
+
+	return s
+}
+
+func projectDescription(p ProjectRef) string {
+	if p.Code == "" {
+		return p.Name
+	}
+
+	return fmt.Sprintf("[%s] %s", p.Code, p.Name)
+}
+
+// Resolves the hourly rate of time entries from their project's assignments, fetching
+// every project, its assignments and user once.
+type invoiceRateResolver struct {
+	client          *HarvestClient
+	projects        map[uint]Project
+	taskAssignments map[uint][]TaskAssignment
+	userAssignments map[uint][]UserAssignment
+	users           map[uint]User
+}
+
+func newInvoiceRateResolver(client *HarvestClient) *invoiceRateResolver {
+	return &invoiceRateResolver{
+		client:          client,
+		projects:        make(map[uint]Project),
+		taskAssignments: make(map[uint][]TaskAssignment),
+		userAssignments: make(map[uint][]UserAssignment),
+		users:           make(map[uint]User),
+	}
+}
+
+func (r *invoiceRateResolver) rate(e TimeEntry) (decimal.Decimal, error) {
+	project, ok := r.projects[e.Project.Id]
+
+	if !ok {
+		resp, err := r.client.Projects.Get(e.Project.Id)
+
+		if err := DecodeResponse(resp, err, &project); err != nil {
+			return decimal.Zero, err
+		}
+
+		r.projects[e.Project.Id] = project
+	}
+
+	switch project.BillBy {
+	case ProjectBilledByProject:
+		return optionalDecimalOrZero(project.HourlyRate), nil
+	case ProjectBilledByTask:
+		assignments, ok := r.taskAssignments[project.Id]
+
+		if !ok {
+			var err error
+
+			if assignments, err = r.client.Projects.GetAllTaskAssignmentPagesForProject(project.Id); err != nil {
+				return decimal.Zero, err
+			}
+
+			r.taskAssignments[project.Id] = assignments
+		}
+
+		for _, a := range assignments {
+			if a.Task.Id == e.Task.Id {
+				return optionalDecimalOrZero(a.HourlyRate), nil
+			}
+		}
+	case ProjectBilledByPeople:
+		assignments, ok := r.userAssignments[project.Id]
+
+		if !ok {
+			var err error
+
+			if assignments, err = r.client.Projects.GetAllUserAssignmentPagesForProject(project.Id); err != nil {
+				return decimal.Zero, err
+			}
+
+			r.userAssignments[project.Id] = assignments
+		}
+
+		for _, a := range assignments {
+			if a.User.Id != e.User.Id {
+				continue
+			}
+
+			if !a.UseDefaultRates {
+				return optionalDecimalOrZero(a.HourlyRate), nil
+			}
+
+			user, ok := r.users[e.User.Id]
+
+			if !ok {
+				resp, err := r.client.Users.GetUser(e.User.Id)
+
+				if err := DecodeResponse(resp, err, &user); err != nil {
+					return decimal.Zero, err
+				}
+
+				r.users[e.User.Id] = user
+			}
+
+			return optionalDecimalOrZero(user.DefaultHourlyRate), nil
+		}
+	}
+
+	return decimal.Zero, nil
+}
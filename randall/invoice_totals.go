@@ -0,0 +1,166 @@
+package randall
+
+import (
+	"fmt"
+
+	"github.com/shopspring/decimal"
+)
+
+var hundred = decimal.NewFromInt(100)
+
+// A line item of an invoice or estimate to calculate totals for.
+type TotalsLineItem struct {
+	Quantity  decimal.Decimal
+	UnitPrice decimal.Decimal
+	// Whether the tax percentage applies to the line item.
+	Taxed bool
+	// Whether the second tax percentage applies to the line item.
+	Taxed2 bool
+}
+
+// The totals of an invoice or estimate, calculated the way Harvest does. Every amount is
+// rounded to the minor units of the currency.
+type InvoiceTotals struct {
+	Currency Currency
+	// The amount of every line item, in order.
+	LineAmounts []decimal.Decimal
+	// The sum of the line item amounts.
+	Subtotal       decimal.Decimal
+	DiscountAmount decimal.Decimal
+	TaxAmount      decimal.Decimal
+	Tax2Amount     decimal.Decimal
+	// The subtotal minus the discount plus both taxes.
+	Amount decimal.Decimal
+}
+
+// Calculates the totals of an invoice or estimate in currency. tax, tax2 and discount are
+// percentages and may be nil. The discount is applied before taxes, so taxes are charged on
+// the discounted amount of the taxed line items.
+func CalculateInvoiceTotals(currency Currency, lineItems []TotalsLineItem, tax, tax2, discount *decimal.Decimal) (InvoiceTotals, error) {
+	units, err := CurrencyMinorUnits(currency)
+
+	if err != nil {
+		return InvoiceTotals{}, err
+	}
+
+	percentages := []struct {
+		name  string
+		value *decimal.Decimal
+	}{{"tax", tax}, {"tax2", tax2}, {"discount", discount}}
+
+	for _, p := range percentages {
+		if p.value != nil && (p.value.IsNegative() || p.value.GreaterThan(hundred)) {
+			return InvoiceTotals{}, fmt.Errorf("%s must be a percentage between 0 and 100, got %s", p.name, p.value)
+		}
+	}
+
+	totals := InvoiceTotals{Currency: currency, LineAmounts: make([]decimal.Decimal, len(lineItems))}
+	taxed, taxed2 := decimal.Zero, decimal.Zero
+
+	for i, item := range lineItems {
+		if item.Quantity.IsNegative() {
+			return InvoiceTotals{}, fmt.Errorf("line item %d: quantity must not be negative", i+1)
+		}
+
+		amount := item.Quantity.Mul(item.UnitPrice).Round(units)
+		totals.LineAmounts[i] = amount
+		totals.Subtotal = totals.Subtotal.Add(amount)
+
+		if item.Taxed {
+			taxed = taxed.Add(amount)
+		}
+
+		if item.Taxed2 {
+			taxed2 = taxed2.Add(amount)
+		}
+	}
+
+	// The share of every amount left after the discount
+	remaining := decimal.NewFromInt(1)
+
+	if discount != nil {
+		totals.DiscountAmount = totals.Subtotal.Mul(*discount).Div(hundred).Round(units)
+		remaining = hundred.Sub(*discount).Div(hundred)
+	}
+
+	if tax != nil {
+		totals.TaxAmount = taxed.Mul(remaining).Mul(*tax).Div(hundred).Round(units)
+	}
+
+	if tax2 != nil {
+		totals.Tax2Amount = taxed2.Mul(remaining).Mul(*tax2).Div(hundred).Round(units)
+	}
+
+	totals.Amount = totals.Subtotal.Sub(totals.DiscountAmount).Add(totals.TaxAmount).Add(totals.Tax2Amount)
+	return totals, nil
+}
+
+// Calculates the totals of the invoice the request creates. clientCurrency is used when the
+// request does not set a currency.
+func (r CreateFreeFormInvoiceRequest) Totals(clientCurrency Currency) (InvoiceTotals, error) {
+	items, err := estimateTotalsLineItems(r.LineItems)
+
+	if err != nil {
+		return InvoiceTotals{}, err
+	}
+
+	return CalculateInvoiceTotals(requestCurrency(r.Currency, clientCurrency), items, r.Tax, r.Tax2, r.Discount)
+}
+
+// Calculates the totals of the estimate the request creates. clientCurrency is used when the
+// request does not set a currency.
+func (r CreateEstimateRequest) Totals(clientCurrency Currency) (InvoiceTotals, error) {
+	items, err := estimateTotalsLineItems(r.LineItems)
+
+	if err != nil {
+		return InvoiceTotals{}, err
+	}
+
+	return CalculateInvoiceTotals(requestCurrency(r.Currency, clientCurrency), items, r.Tax, r.Tax2, r.Discount)
+}
+
+// Calculates the totals of an invoice from its line items and percentages, to check them
+// against the totals sent by Harvest.
+func (inv Invoice) CalculateTotals() (InvoiceTotals, error) {
+	items := make([]TotalsLineItem, len(inv.LineItems))
+
+	for i, item := range inv.LineItems {
+		items[i] = TotalsLineItem{Quantity: item.Quantity, UnitPrice: item.UnitPrice, Taxed: item.Taxed, Taxed2: item.Taxed2}
+	}
+
+	return CalculateInvoiceTotals(inv.Currency, items, inv.Tax, inv.Tax2, inv.Discount)
+}
+
+// Line items default to a quantity of 1, a unit price of 0 and being untaxed, like in Harvest.
+func estimateTotalsLineItems(lineItems []CreateEstimateLineItemRequest) ([]TotalsLineItem, error) {
+	items := make([]TotalsLineItem, len(lineItems))
+
+	for i, item := range lineItems {
+		if item.Kind == "" {
+			return nil, fmt.Errorf("line item %d: kind is required", i+1)
+		}
+
+		items[i] = TotalsLineItem{Quantity: decimal.NewFromInt(1)}
+
+		if item.Quantity != nil {
+			items[i].Quantity = decimal.NewFromInt(int64(*item.Quantity))
+		}
+
+		if item.UnitPrice != nil {
+			items[i].UnitPrice = *item.UnitPrice
+		}
+
+		items[i].Taxed = item.Taxed != nil && *item.Taxed
+		items[i].Taxed2 = item.Taxed2 != nil && *item.Taxed2
+	}
+
+	return items, nil
+}
+
+func requestCurrency(currency *Currency, clientCurrency Currency) Currency {
+	if currency != nil {
+		return *currency
+	}
+
+	return clientCurrency
+}
@@ -0,0 +1,130 @@
+package randall
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestCalculateInvoiceTotals(t *testing.T) {
+	d := decimal.RequireFromString
+	percent := func(s string) *decimal.Decimal {
+		p := d(s)
+		return &p
+	}
+	item := func(quantity, unitPrice string, taxed, taxed2 bool) TotalsLineItem {
+		return TotalsLineItem{Quantity: d(quantity), UnitPrice: d(unitPrice), Taxed: taxed, Taxed2: taxed2}
+	}
+
+	tests := []struct {
+		name      string
+		currency  Currency
+		lineItems []TotalsLineItem
+		tax       *decimal.Decimal
+		tax2      *decimal.Decimal
+		discount  *decimal.Decimal
+		// subtotal, discount, tax, tax2 and amount
+		want    [5]string
+		wantErr bool
+	}{
+		{
+			name:     "no line items",
+			currency: UnitedStatesDollar,
+			want:     [5]string{"0", "0", "0", "0", "0"},
+		},
+		{
+			name:      "line amounts rounded to cents",
+			currency:  UnitedStatesDollar,
+			lineItems: []TotalsLineItem{item("3", "33.333", false, false)},
+			want:      [5]string{"100", "0", "0", "0", "100"},
+		},
+		{
+			name:      "tax only on taxed line items",
+			currency:  UnitedStatesDollar,
+			lineItems: []TotalsLineItem{item("2", "50", true, false), item("1", "30", false, false)},
+			tax:       percent("10"),
+			want:      [5]string{"130", "0", "10", "0", "140"},
+		},
+		{
+			name:      "tax on the discounted amount",
+			currency:  UnitedStatesDollar,
+			lineItems: []TotalsLineItem{item("1", "100", true, false)},
+			tax:       percent("8.25"),
+			discount:  percent("10"),
+			want:      [5]string{"100", "10", "7.43", "0", "97.43"},
+		},
+		{
+			name:      "both taxes",
+			currency:  UnitedStatesDollar,
+			lineItems: []TotalsLineItem{item("1", "200", true, true), item("1", "100", false, true)},
+			tax:       percent("5"),
+			tax2:      percent("2.5"),
+			want:      [5]string{"300", "0", "10", "7.5", "317.5"},
+		},
+		{
+			name:      "currency without minor units",
+			currency:  JapaneseYen,
+			lineItems: []TotalsLineItem{item("3", "33.5", true, false)},
+			tax:       percent("10"),
+			want:      [5]string{"101", "0", "10", "0", "111"},
+		},
+		{
+			name:      "full discount",
+			currency:  UnitedStatesDollar,
+			lineItems: []TotalsLineItem{item("1", "100", true, false)},
+			tax:       percent("20"),
+			discount:  percent("100"),
+			want:      [5]string{"100", "100", "0", "0", "0"},
+		},
+		{
+			name:     "unsupported currency",
+			currency: Currency("ZZZ"),
+			wantErr:  true,
+		},
+		{
+			name:     "tax above 100 percent",
+			currency: UnitedStatesDollar,
+			tax:      percent("101"),
+			wantErr:  true,
+		},
+		{
+			name:     "negative discount",
+			currency: UnitedStatesDollar,
+			discount: percent("-1"),
+			wantErr:  true,
+		},
+		{
+			name:      "negative quantity",
+			currency:  UnitedStatesDollar,
+			lineItems: []TotalsLineItem{item("-1", "10", false, false)},
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			totals, err := CalculateInvoiceTotals(tt.currency, tt.lineItems, tt.tax, tt.tax2, tt.discount)
+
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got %+v", totals)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got := [5]decimal.Decimal{totals.Subtotal, totals.DiscountAmount, totals.TaxAmount, totals.Tax2Amount, totals.Amount}
+			names := [5]string{"subtotal", "discount", "tax", "tax2", "amount"}
+
+			for i, want := range tt.want {
+				if !got[i].Equal(d(want)) {
+					t.Errorf("%s = %s, want %s", names[i], got[i], want)
+				}
+			}
+		})
+	}
+}
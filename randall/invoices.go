@@ -1,12 +1,58 @@
 package randall
 
 import (
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/shopspring/decimal"
 )
 
+// The state of an invoice.
+type InvoiceState string
+
+// How tracked time or expenses are summarized into invoice line items.
+type SummaryType string
+
+// The item category of an invoice or estimate line item. Categories are defined per
+// account, LineItemKindService and LineItemKindProduct exist by default.
+type LineItemKind string
+
+const (
+	InvoiceStateDraft  InvoiceState = "draft"
+	InvoiceStateOpen   InvoiceState = "open"
+	InvoiceStatePaid   InvoiceState = "paid"
+	InvoiceStateClosed InvoiceState = "closed"
+
+	// Summary types of time imported into an invoice
+	InvoiceTimeSummaryByProject SummaryType = "project"
+	InvoiceTimeSummaryByTask    SummaryType = "task"
+	InvoiceTimeSummaryByPeople  SummaryType = "people"
+	InvoiceTimeSummaryDetailed  SummaryType = "detailed"
+
+	// Summary types of expenses imported into an invoice
+	InvoiceExpenseSummaryByProject  SummaryType = "project"
+	InvoiceExpenseSummaryByCategory SummaryType = "category"
+	InvoiceExpenseSummaryByPeople   SummaryType = "people"
+	InvoiceExpenseSummaryDetailed   SummaryType = "detailed"
+
+	LineItemKindService LineItemKind = "Service"
+	LineItemKindProduct LineItemKind = "Product"
+)
+
+var invoiceStates = []InvoiceState{InvoiceStateDraft, InvoiceStateOpen, InvoiceStatePaid, InvoiceStateClosed}
+
+var summaryTypes = []SummaryType{
+	InvoiceTimeSummaryByProject,
+	InvoiceTimeSummaryByTask,
+	InvoiceTimeSummaryByPeople,
+	InvoiceTimeSummaryDetailed,
+	InvoiceExpenseSummaryByCategory,
+}
+
+var lineItemKinds = []LineItemKind{LineItemKindService, LineItemKindProduct}
+
 // Encapsulates the Harvest API methods under /projects
 type InvoicesApi struct {
 	baseUrl               string
@@ -14,6 +60,76 @@ type InvoicesApi struct {
 	client                *internalClient
 }
 
+// A minimal representation of an invoice embedded in a Harvest payload.
+type InvoiceRef struct {
+	Id     uint   `json:"id"`
+	Number string `json:"number"`
+}
+
+// An invoice as returned by the Harvest API.
+type Invoice struct {
+	Id             uint              `json:"id"`
+	Client         ClientRef         `json:"client"`
+	LineItems      []InvoiceLineItem `json:"line_items"`
+	Estimate       *ObjectRef        `json:"estimate"`
+	Retainer       *ObjectRef        `json:"retainer"`
+	Creator        ObjectRef         `json:"creator"`
+	ClientKey      string            `json:"client_key"`
+	Number         string            `json:"number"`
+	PurchaseOrder  string            `json:"purchase_order"`
+	Amount         decimal.Decimal   `json:"amount"`
+	DueAmount      decimal.Decimal   `json:"due_amount"`
+	Tax            *decimal.Decimal  `json:"tax"`
+	TaxAmount      decimal.Decimal   `json:"tax_amount"`
+	Tax2           *decimal.Decimal  `json:"tax2"`
+	Tax2Amount     decimal.Decimal   `json:"tax2_amount"`
+	Discount       *decimal.Decimal  `json:"discount"`
+	DiscountAmount decimal.Decimal   `json:"discount_amount"`
+	Subject        string            `json:"subject"`
+	Notes          string            `json:"notes"`
+	Currency       Currency          `json:"currency"`
+	State          InvoiceState      `json:"state"`
+	PeriodStart    HarvestDate       `json:"period_start"`
+	PeriodEnd      HarvestDate       `json:"period_end"`
+	IssueDate      HarvestDate       `json:"issue_date"`
+	DueDate        HarvestDate       `json:"due_date"`
+	PaymentTerm    string            `json:"payment_term"`
+	SentAt         *time.Time        `json:"sent_at"`
+	PaidAt         *time.Time        `json:"paid_at"`
+	PaidDate       HarvestDate       `json:"paid_date"`
+	ClosedAt       *time.Time        `json:"closed_at"`
+	CreatedAt      time.Time         `json:"created_at"`
+	UpdatedAt      time.Time         `json:"updated_at"`
+}
+
+// A line item of an invoice as returned by the Harvest API.
+type InvoiceLineItem struct {
+	Id          uint            `json:"id"`
+	Project     *ProjectRef     `json:"project"`
+	Kind        LineItemKind    `json:"kind"`
+	Description string          `json:"description"`
+	Quantity    decimal.Decimal `json:"quantity"`
+	UnitPrice   decimal.Decimal `json:"unit_price"`
+	Amount      decimal.Decimal `json:"amount"`
+	Taxed       bool            `json:"taxed"`
+	Taxed2      bool            `json:"taxed2"`
+}
+
+// A payment recorded for an invoice as returned by the Harvest API.
+type InvoicePayment struct {
+	Id              uint            `json:"id"`
+	Amount          decimal.Decimal `json:"amount"`
+	PaidAt          *time.Time      `json:"paid_at"`
+	PaidDate        HarvestDate     `json:"paid_date"`
+	RecordedBy      string          `json:"recorded_by"`
+	RecordedByEmail string          `json:"recorded_by_email"`
+	Notes           string          `json:"notes"`
+	TransactionId   string          `json:"transaction_id"`
+	PaymentGateway  *ObjectRef      `json:"payment_gateway"`
+	CreatedAt       time.Time       `json:"created_at"`
+	UpdatedAt       time.Time       `json:"updated_at"`
+}
+
 type CreateFreeFormInvoiceRequest struct {
 	ClientId      uint                            `json:"client_id"`
 	RetainerId    *uint                           `json:"retainer_id,omitempty"`
@@ -25,7 +141,7 @@ type CreateFreeFormInvoiceRequest struct {
 	Discount      *decimal.Decimal                `json:"discount,omitempty"`
 	Subject       *string                         `json:"subject,omitempty"`
 	Notes         *string                         `json:"notes,omitempty"`
-	Currency      *string                         `json:"currency,omitempty"`
+	Currency      *Currency                       `json:"currency,omitempty"`
 	IssueDate     *time.Time                      `json:"issue_date,omitempty" layout:"2006-01-02"`
 	DueDate       *time.Time                      `json:"due_date,omitempty" layout:"2006-01-02"`
 	PaymentTerm   *string                         `json:"payment_term,omitempty"`
@@ -43,7 +159,7 @@ type CreateInvoiceFromTrackedTimeAndExpenseRequest struct {
 	Discount        *decimal.Decimal              `json:"discount,omitempty"`
 	Subject         *string                       `json:"subject,omitempty"`
 	Notes           *string                       `json:"notes,omitempty"`
-	Currency        *string                       `json:"currency,omitempty"`
+	Currency        *Currency                     `json:"currency,omitempty"`
 	IssueDate       *time.Time                    `json:"issue_date,omitempty" layout:"2006-01-02"`
 	DueDate         *time.Time                    `json:"due_date,omitempty" layout:"2006-01-02"`
 	PaymentTerm     *string                       `json:"payment_term,omitempty"`
@@ -51,7 +167,7 @@ type CreateInvoiceFromTrackedTimeAndExpenseRequest struct {
 }
 
 type CreateInvoiceLineItemRequest struct {
-	Kind        string           `json:"kind"`
+	Kind        LineItemKind     `json:"kind"`
 	ProjectId   *uint            `json:"project_id,omitempty"`
 	Description *string          `json:"description,omitempty"`
 	Quantity    *uint            `json:"quantity,omitempty"`
@@ -61,22 +177,22 @@ type CreateInvoiceLineItemRequest struct {
 }
 
 type CreateLineItemsImportRequest struct {
-	ProjectIds []uint         `json:"project_ids"`
-	Time       *TimeImport    `json:"time,omitempty"`
-	Expenses   ExpensesImport `json:"expenses,omitempty"`
+	ProjectIds []uint          `json:"project_ids"`
+	Time       *TimeImport     `json:"time,omitempty"`
+	Expenses   *ExpensesImport `json:"expenses,omitempty"`
 }
 
 type TimeImport struct {
-	SummaryType string     `json:"summary_type"`
-	From        *time.Time `json:"from,omitempty" layout:"2006-01-02"`
-	To          *time.Time `json:"to,omitempty" layout:"2006-01-02"`
+	SummaryType SummaryType `json:"summary_type"`
+	From        *time.Time  `json:"from,omitempty" layout:"2006-01-02"`
+	To          *time.Time  `json:"to,omitempty" layout:"2006-01-02"`
 }
 
 type ExpensesImport struct {
-	SummaryType    string     `json:"summary_type"`
-	From           *time.Time `json:"from,omitempty" layout:"2006-01-02"`
-	To             *time.Time `json:"to,omitempty" layout:"2006-01-02"`
-	AttachReceipts *bool      `json:"attach_receipts,omitempty"`
+	SummaryType    SummaryType `json:"summary_type"`
+	From           *time.Time  `json:"from,omitempty" layout:"2006-01-02"`
+	To             *time.Time  `json:"to,omitempty" layout:"2006-01-02"`
+	AttachReceipts *bool       `json:"attach_receipts,omitempty"`
 }
 
 type UpdateInvoiceRequest struct {
@@ -90,7 +206,7 @@ type UpdateInvoiceRequest struct {
 	Discount      *decimal.Decimal               `json:"discount,omitempty"`
 	Subject       *string                        `json:"subject,omitempty"`
 	Notes         *string                        `json:"notes,omitempty"`
-	Currency      *string                        `json:"currency,omitempty"`
+	Currency      *Currency                      `json:"currency,omitempty"`
 	IssueDate     *time.Time                     `json:"issue_date,omitempty" layout:"2006-01-02"`
 	DueDate       *time.Time                     `json:"due_date,omitempty" layout:"2006-01-02"`
 	PaymentTerm   *string                        `json:"payment_term,omitempty"`
@@ -100,7 +216,7 @@ type UpdateInvoiceRequest struct {
 type UpdateInvoiceLineItemRequest struct {
 	Id          *uint            `json:"id,omitempty"`
 	ProjectId   *uint            `json:"project_id,omitempty"`
-	Kind        *string          `json:"kind,omitempty"`
+	Kind        *LineItemKind    `json:"kind,omitempty"`
 	Description *string          `json:"description,omitempty"`
 	Quantity    *uint            `json:"quantity,omitempty"`
 	UnitPrice   *decimal.Decimal `json:"unit_price,omitempty"`
@@ -127,6 +243,84 @@ type CreateInvoicePaymentRequest struct {
 	Notes    *string         `json:"notes,omitempty"`
 }
 
+// Parses the state of an invoice, ignoring case.
+func ParseInvoiceState(s string) (InvoiceState, error) {
+	return parseEnum("invoice state", invoiceStates, s)
+}
+
+// Returns whether the invoice state is known to Harvest.
+func (s InvoiceState) Valid() bool {
+	return containsEnum(invoiceStates, s)
+}
+
+func (s InvoiceState) String() string {
+	return string(s)
+}
+
+func (s InvoiceState) MarshalJSON() ([]byte, error) {
+	return marshalEnum(s)
+}
+
+func (s *InvoiceState) UnmarshalJSON(b []byte) (err error) {
+	*s, err = unmarshalEnum(b, ParseInvoiceState)
+	return err
+}
+
+// Parses a summary type, ignoring case.
+func ParseSummaryType(s string) (SummaryType, error) {
+	return parseEnum("summary type", summaryTypes, s)
+}
+
+// Returns whether the summary type is known to Harvest. Time can't be summarized by
+// category.
+func (t SummaryType) Valid() bool {
+	return containsEnum(summaryTypes, t)
+}
+
+func (t SummaryType) String() string {
+	return string(t)
+}
+
+func (t SummaryType) MarshalJSON() ([]byte, error) {
+	return marshalEnum(t)
+}
+
+func (t *SummaryType) UnmarshalJSON(b []byte) (err error) {
+	*t, err = unmarshalEnum(b, ParseSummaryType)
+	return err
+}
+
+// Parses a line item kind. The default kinds are matched ignoring case, other kinds are
+// returned as is since categories are defined per account.
+func ParseLineItemKind(s string) (LineItemKind, error) {
+	kind, err := parseEnum("line item kind", lineItemKinds, s)
+
+	if err != nil && kind == "" {
+		return kind, errors.New("line item kind is empty")
+	}
+
+	return kind, nil
+}
+
+// Returns whether the kind can name an item category, i.e. is neither empty nor padded
+// with whitespace.
+func (k LineItemKind) Valid() bool {
+	return k != "" && strings.TrimSpace(string(k)) == string(k)
+}
+
+func (k LineItemKind) String() string {
+	return string(k)
+}
+
+func (k LineItemKind) MarshalJSON() ([]byte, error) {
+	return marshalEnum(k)
+}
+
+func (k *LineItemKind) UnmarshalJSON(b []byte) (err error) {
+	*k, err = unmarshalEnum(b, ParseLineItemKind)
+	return err
+}
+
 func newInvoicesV2(client *internalClient) InvoicesApi {
 	return InvoicesApi{
 		baseUrl:               "v2/invoices",
@@ -139,6 +333,11 @@ func (api InvoicesApi) GetAll(params ...HarvestCollectionParams) (HarvestRespons
 	return api.client.doGet(api.baseUrl, getOptionalCollectionParams(params))
 }
 
+// Retrieves every page of invoices matching params as typed Invoice objects.
+func (api InvoicesApi) GetAllPages(params ...HarvestCollectionParams) ([]Invoice, error) {
+	return getAllCollectionPages[Invoice]("invoices", params, api.GetAll)
+}
+
 func (api InvoicesApi) Get(invoiceId uint) (HarvestResponse, error) {
 	return api.client.doGet(fmt.Sprintf("%s/%d", api.baseUrl, invoiceId))
 }
@@ -180,8 +379,8 @@ func (api InvoicesApi) UpdateInvoiceItemCategory(invoiceItemCategoryItemId uint,
 		})
 }
 
-func (api EstimatesApi) DeleteInvoiceItemCategory(estimateCategoryItemId uint) (HarvestResponse, error) {
-	return api.client.doDelete(fmt.Sprintf("%s/%d", api.estimateItemCategoriesBaseUrl, estimateCategoryItemId))
+func (api InvoicesApi) DeleteInvoiceItemCategory(invoiceItemCategoryItemId uint) (HarvestResponse, error) {
+	return api.client.doDelete(fmt.Sprintf("%s/%d", api.itemCategoriesBaseUrl, invoiceItemCategoryItemId))
 }
 
 func (api InvoicesApi) GetAllInvoiceMessages(invoiceId uint, params ...HarvestCollectionParams) (HarvestResponse, error) {
@@ -196,15 +395,20 @@ func (api InvoicesApi) CreateInvoiceMessage(invoiceId uint, req CreateInvoiceMes
 	return api.client.doPost(fmt.Sprintf("%s/%d/messages", api.baseUrl, invoiceId), req)
 }
 
-func (api InvoicesApi) MarkDraftEstimateSent(invoiceId uint) (HarvestResponse, error) {
+func (api InvoicesApi) MarkDraftInvoiceSent(invoiceId uint) (HarvestResponse, error) {
 	return api.client.doPost(
 		fmt.Sprintf("%s/%d/messages", api.baseUrl, invoiceId),
 		getUpdateEventTypeRequest("send"))
 }
 
+// Deprecated: use MarkDraftInvoiceSent.
+func (api InvoicesApi) MarkDraftEstimateSent(invoiceId uint) (HarvestResponse, error) {
+	return api.MarkDraftInvoiceSent(invoiceId)
+}
+
 func (api InvoicesApi) MarkOpenInvoiceClosed(invoiceId uint) (HarvestResponse, error) {
 	return api.client.doPost(
-		fmt.Sprintf("%s/%d/messages", api.client.baseUrl, invoiceId),
+		fmt.Sprintf("%s/%d/messages", api.baseUrl, invoiceId),
 		getUpdateEventTypeRequest("close"))
 }
 
@@ -228,6 +432,13 @@ func (api InvoicesApi) GetAllInvoicePayments(invoiceId uint, params ...HarvestCo
 	return api.client.doGet(fmt.Sprintf("%s/%d/payments", api.baseUrl, invoiceId), getOptionalCollectionParams(params))
 }
 
+// Retrieves every page of the payments of an invoice as typed InvoicePayment objects.
+func (api InvoicesApi) GetAllInvoicePaymentPages(invoiceId uint, params ...HarvestCollectionParams) ([]InvoicePayment, error) {
+	return getAllCollectionPages[InvoicePayment]("invoice_payments", params, func(params ...HarvestCollectionParams) (HarvestResponse, error) {
+		return api.GetAllInvoicePayments(invoiceId, params...)
+	})
+}
+
 func (api InvoicesApi) CreateInvoicePayment(invoiceId uint, req CreateInvoicePaymentRequest) (HarvestResponse, error) {
 	return api.client.doPost(fmt.Sprintf("%s/%d/payments", api.baseUrl, invoiceId), req)
 }
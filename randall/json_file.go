@@ -0,0 +1,80 @@
+package randall
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+)
+
+// A map kept in a JSON file. The file is read on every access and replaced atomically on
+// every write, so it can be shared between runs.
+type jsonFileMap[K comparable, V any] struct {
+	mu   sync.Mutex
+	path string
+}
+
+// Returns the value of key, and whether the file has one.
+func (f *jsonFileMap[K, V]) get(key K) (V, bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var value V
+	values, err := f.read()
+
+	if err != nil {
+		return value, false, err
+	}
+
+	value, ok := values[key]
+	return value, ok, nil
+}
+
+// Sets the value of key, creating the file if it doesn't exist.
+func (f *jsonFileMap[K, V]) set(key K, value V) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	values, err := f.read()
+
+	if err != nil {
+		return err
+	}
+
+	values[key] = value
+
+	b, err := json.MarshalIndent(values, "", "  ")
+
+	if err != nil {
+		return err
+	}
+
+	tmp := f.path + ".tmp"
+
+	if err := os.WriteFile(tmp, b, 0o644); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, f.path)
+}
+
+func (f *jsonFileMap[K, V]) read() (map[K]V, error) {
+	values := make(map[K]V)
+
+	b, err := os.ReadFile(f.path)
+
+	if errors.Is(err, os.ErrNotExist) {
+		return values, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(b, &values); err != nil {
+		return nil, fmt.Errorf("%s: %w", f.path, err)
+	}
+
+	return values, nil
+}
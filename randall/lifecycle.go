@@ -0,0 +1,245 @@
+package randall
+
+import (
+	"fmt"
+	"strings"
+)
+
+// An event moving an invoice from one state to another.
+type InvoiceEvent string
+
+// An event moving an estimate from one state to another.
+type EstimateEvent string
+
+const (
+	// Marks a draft invoice as sent, opening it.
+	InvoiceEventSend InvoiceEvent = "send"
+	// Closes an open invoice, e.g. to write it off.
+	InvoiceEventClose InvoiceEvent = "close"
+	// Re-opens a closed invoice.
+	InvoiceEventReopen InvoiceEvent = "re-open"
+	// Turns an open invoice back into a draft.
+	InvoiceEventDraft InvoiceEvent = "draft"
+
+	// Marks a draft estimate as sent.
+	EstimateEventSend EstimateEvent = "send"
+	// Marks a sent estimate as accepted.
+	EstimateEventAccept EstimateEvent = "accept"
+	// Marks a sent estimate as declined.
+	EstimateEventDecline EstimateEvent = "decline"
+	// Re-opens an accepted or declined estimate.
+	EstimateEventReopen EstimateEvent = "re-open"
+)
+
+// Returned when an event is not allowed in the current state of an invoice or estimate.
+type InvalidTransitionError struct {
+	// "invoice" or "estimate".
+	Resource string
+	Id       uint
+	State    string
+	Event    string
+	// The states the event is allowed in.
+	Allowed []string
+}
+
+type stateTransition[S ~string] struct {
+	from []S
+	to   S
+}
+
+// The invoice states every event is allowed in and the state it leads to. Invoices are
+// paid by recording payments, see InvoicesApi.Pay.
+var invoiceTransitions = map[InvoiceEvent]stateTransition[InvoiceState]{
+	InvoiceEventSend:   {from: []InvoiceState{InvoiceStateDraft}, to: InvoiceStateOpen},
+	InvoiceEventClose:  {from: []InvoiceState{InvoiceStateOpen}, to: InvoiceStateClosed},
+	InvoiceEventReopen: {from: []InvoiceState{InvoiceStateClosed}, to: InvoiceStateOpen},
+	InvoiceEventDraft:  {from: []InvoiceState{InvoiceStateOpen}, to: InvoiceStateDraft},
+}
+
+// The estimate states every event is allowed in and the state it leads to.
+var estimateTransitions = map[EstimateEvent]stateTransition[EstimateState]{
+	EstimateEventSend:    {from: []EstimateState{EstimateStateDraft}, to: EstimateStateSent},
+	EstimateEventAccept:  {from: []EstimateState{EstimateStateSent}, to: EstimateStateAccepted},
+	EstimateEventDecline: {from: []EstimateState{EstimateStateSent}, to: EstimateStateDeclined},
+	EstimateEventReopen:  {from: []EstimateState{EstimateStateAccepted, EstimateStateDeclined}, to: EstimateStateSent},
+}
+
+func (e *InvalidTransitionError) Error() string {
+	return fmt.Sprintf("cannot %s %s %d in state %s, allowed in: %s",
+		e.Event, e.Resource, e.Id, e.State, strings.Join(e.Allowed, ", "))
+}
+
+// Returns the state an invoice in the given state is in after the event, or an
+// *InvalidTransitionError if the event is not allowed in that state.
+func NextInvoiceState(state InvoiceState, event InvoiceEvent) (InvoiceState, error) {
+	transition, ok := invoiceTransitions[event]
+
+	if !ok {
+		return "", fmt.Errorf("unknown invoice event %q", event)
+	}
+
+	return transition.next("invoice", state, string(event))
+}
+
+// Returns the state an estimate in the given state is in after the event, or an
+// *InvalidTransitionError if the event is not allowed in that state.
+func NextEstimateState(state EstimateState, event EstimateEvent) (EstimateState, error) {
+	transition, ok := estimateTransitions[event]
+
+	if !ok {
+		return "", fmt.Errorf("unknown estimate event %q", event)
+	}
+
+	return transition.next("estimate", state, string(event))
+}
+
+func (t stateTransition[S]) next(resource string, state S, event string) (S, error) {
+	if !containsEnum(t.from, state) {
+		allowed := make([]string, len(t.from))
+
+		for i, from := range t.from {
+			allowed[i] = string(from)
+		}
+
+		return "", &InvalidTransitionError{Resource: resource, State: string(state), Event: event, Allowed: allowed}
+	}
+
+	return t.to, nil
+}
+
+// Applies the event to an invoice after checking it is allowed in the invoice's current
+// state, and returns the updated invoice.
+func (api InvoicesApi) Transition(invoiceId uint, event InvoiceEvent) (Invoice, error) {
+	var invoice Invoice
+	resp, err := api.Get(invoiceId)
+
+	if err := DecodeResponse(resp, err, &invoice); err != nil {
+		return invoice, err
+	}
+
+	if _, err := NextInvoiceState(invoice.State, event); err != nil {
+		if transitionErr, ok := err.(*InvalidTransitionError); ok {
+			transitionErr.Id = invoiceId
+		}
+
+		return invoice, err
+	}
+
+	resp, err = api.markEvent(invoiceId, event)
+
+	if err == nil {
+		err = checkResponse(resp)
+	}
+
+	if err != nil {
+		return invoice, err
+	}
+
+	resp, err = api.Get(invoiceId)
+	err = DecodeResponse(resp, err, &invoice)
+	return invoice, err
+}
+
+// Posts the event through the invoice's Mark method.
+func (api InvoicesApi) markEvent(invoiceId uint, event InvoiceEvent) (HarvestResponse, error) {
+	switch event {
+	case InvoiceEventSend:
+		return api.MarkDraftInvoiceSent(invoiceId)
+	case InvoiceEventClose:
+		return api.MarkOpenInvoiceClosed(invoiceId)
+	case InvoiceEventReopen:
+		return api.ReopenCloseInvoice(invoiceId)
+	case InvoiceEventDraft:
+		return api.MarkOpenInvoiceDraft(invoiceId)
+	default:
+		return HarvestResponse{}, fmt.Errorf("unknown invoice event %q", event)
+	}
+}
+
+// Records a payment for an open invoice and returns the updated invoice, which is paid
+// once its due amount is covered.
+func (api InvoicesApi) Pay(invoiceId uint, req CreateInvoicePaymentRequest) (Invoice, error) {
+	var invoice Invoice
+	resp, err := api.Get(invoiceId)
+
+	if err := DecodeResponse(resp, err, &invoice); err != nil {
+		return invoice, err
+	}
+
+	if invoice.State != InvoiceStateOpen {
+		return invoice, &InvalidTransitionError{
+			Resource: "invoice",
+			Id:       invoiceId,
+			State:    string(invoice.State),
+			Event:    "pay",
+			Allowed:  []string{string(InvoiceStateOpen)},
+		}
+	}
+
+	if req.Amount.GreaterThan(invoice.DueAmount) {
+		return invoice, fmt.Errorf("payment of %s exceeds the due amount %s of invoice %d", req.Amount, invoice.DueAmount, invoiceId)
+	}
+
+	resp, err = api.CreateInvoicePayment(invoiceId, req)
+
+	if err == nil {
+		err = checkResponse(resp)
+	}
+
+	if err != nil {
+		return invoice, err
+	}
+
+	resp, err = api.Get(invoiceId)
+	err = DecodeResponse(resp, err, &invoice)
+	return invoice, err
+}
+
+// Applies the event to an estimate after checking it is allowed in the estimate's current
+// state, and returns the updated estimate.
+func (api EstimatesApi) Transition(estimateId uint, event EstimateEvent) (Estimate, error) {
+	var estimate Estimate
+	resp, err := api.Get(estimateId)
+
+	if err := DecodeResponse(resp, err, &estimate); err != nil {
+		return estimate, err
+	}
+
+	if _, err := NextEstimateState(estimate.State, event); err != nil {
+		if transitionErr, ok := err.(*InvalidTransitionError); ok {
+			transitionErr.Id = estimateId
+		}
+
+		return estimate, err
+	}
+
+	resp, err = api.markEvent(estimateId, event)
+
+	if err == nil {
+		err = checkResponse(resp)
+	}
+
+	if err != nil {
+		return estimate, err
+	}
+
+	resp, err = api.Get(estimateId)
+	err = DecodeResponse(resp, err, &estimate)
+	return estimate, err
+}
+
+// Posts the event through the estimate's Mark method.
+func (api EstimatesApi) markEvent(estimateId uint, event EstimateEvent) (HarvestResponse, error) {
+	switch event {
+	case EstimateEventSend:
+		return api.MarkDraftEstimateSent(estimateId)
+	case EstimateEventAccept:
+		return api.MarkEstimateAccepted(estimateId)
+	case EstimateEventDecline:
+		return api.MarkEstimateDeclined(estimateId)
+	case EstimateEventReopen:
+		return api.ReopenClosedEstimate(estimateId)
+	default:
+		return HarvestResponse{}, fmt.Errorf("unknown estimate event %q", event)
+	}
+}
@@ -0,0 +1,637 @@
+package randall
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Mirrors a Harvest account into a normalized SQLite database, so the data can be
+// queried with SQL without hitting the Harvest API. The caller opens the database
+// with the SQLite driver of their choice.
+type Mirror struct {
+	client *HarvestClient
+	db     *sql.DB
+}
+
+// A column of a mirrored table, read from the dotted JSON path of the Harvest object.
+type mirrorColumn struct {
+	name    string
+	sqlType string
+	path    string
+}
+
+// A table holding the objects of a nested array, e.g. the line items of an invoice.
+type mirrorChildTable struct {
+	name string
+	// The column referencing the parent object's id.
+	parentColumn string
+	// The JSON path of the array in the parent object.
+	path string
+	// The columns of the child table. When the array holds plain values rather than
+	// objects, the single column is read with an empty path.
+	columns []mirrorColumn
+}
+
+type mirrorTable struct {
+	name     string
+	resource SyncResource
+	// The column holding the date Harvest filters the resource by, if any.
+	dateColumn string
+	columns    []mirrorColumn
+	children   []mirrorChildTable
+}
+
+var mirrorTables = []mirrorTable{
+	{
+		name:     "clients",
+		resource: SyncClients,
+		columns: []mirrorColumn{
+			{"name", "TEXT", "name"},
+			{"is_active", "INTEGER", "is_active"},
+			{"address", "TEXT", "address"},
+			{"statement_key", "TEXT", "statement_key"},
+			{"currency", "TEXT", "currency"},
+		},
+	},
+	{
+		name:     "contacts",
+		resource: SyncContacts,
+		columns: []mirrorColumn{
+			{"client_id", "INTEGER", "client.id"},
+			{"title", "TEXT", "title"},
+			{"first_name", "TEXT", "first_name"},
+			{"last_name", "TEXT", "last_name"},
+			{"email", "TEXT", "email"},
+			{"phone_office", "TEXT", "phone_office"},
+			{"phone_mobile", "TEXT", "phone_mobile"},
+			{"fax", "TEXT", "fax"},
+		},
+	},
+	{
+		name:     "roles",
+		resource: SyncRoles,
+		columns: []mirrorColumn{
+			{"name", "TEXT", "name"},
+		},
+		children: []mirrorChildTable{
+			{
+				name:         "role_users",
+				parentColumn: "role_id",
+				path:         "user_ids",
+				columns: []mirrorColumn{
+					{"user_id", "INTEGER", ""},
+				},
+			},
+		},
+	},
+	{
+		name:     "users",
+		resource: SyncUsers,
+		columns: []mirrorColumn{
+			{"first_name", "TEXT", "first_name"},
+			{"last_name", "TEXT", "last_name"},
+			{"email", "TEXT", "email"},
+			{"telephone", "TEXT", "telephone"},
+			{"timezone", "TEXT", "timezone"},
+			{"has_access_to_all_future_projects", "INTEGER", "has_access_to_all_future_projects"},
+			{"is_contractor", "INTEGER", "is_contractor"},
+			{"is_active", "INTEGER", "is_active"},
+			{"weekly_capacity", "INTEGER", "weekly_capacity"},
+			{"default_hourly_rate", "NUMERIC", "default_hourly_rate"},
+			{"cost_rate", "NUMERIC", "cost_rate"},
+			{"roles", "TEXT", "roles"},
+			{"access_roles", "TEXT", "access_roles"},
+		},
+	},
+	{
+		name:     "tasks",
+		resource: SyncTasks,
+		columns: []mirrorColumn{
+			{"name", "TEXT", "name"},
+			{"billable_by_default", "INTEGER", "billable_by_default"},
+			{"default_hourly_rate", "NUMERIC", "default_hourly_rate"},
+			{"is_default", "INTEGER", "is_default"},
+			{"is_active", "INTEGER", "is_active"},
+		},
+	},
+	{
+		name:     "projects",
+		resource: SyncProjects,
+		columns: []mirrorColumn{
+			{"client_id", "INTEGER", "client.id"},
+			{"name", "TEXT", "name"},
+			{"code", "TEXT", "code"},
+			{"is_active", "INTEGER", "is_active"},
+			{"is_billable", "INTEGER", "is_billable"},
+			{"is_fixed_fee", "INTEGER", "is_fixed_fee"},
+			{"bill_by", "TEXT", "bill_by"},
+			{"hourly_rate", "NUMERIC", "hourly_rate"},
+			{"budget", "NUMERIC", "budget"},
+			{"budget_by", "TEXT", "budget_by"},
+			{"budget_is_monthly", "INTEGER", "budget_is_monthly"},
+			{"notify_when_over_budget", "INTEGER", "notify_when_over_budget"},
+			{"over_budget_notification_percentage", "NUMERIC", "over_budget_notification_percentage"},
+			{"show_budget_to_all", "INTEGER", "show_budget_to_all"},
+			{"cost_budget", "NUMERIC", "cost_budget"},
+			{"cost_budget_include_expenses", "INTEGER", "cost_budget_include_expenses"},
+			{"fee", "NUMERIC", "fee"},
+			{"notes", "TEXT", "notes"},
+			{"starts_on", "TEXT", "starts_on"},
+			{"ends_on", "TEXT", "ends_on"},
+		},
+	},
+	{
+		name:     "user_assignments",
+		resource: SyncUserAssignments,
+		columns: []mirrorColumn{
+			{"project_id", "INTEGER", "project.id"},
+			{"user_id", "INTEGER", "user.id"},
+			{"is_active", "INTEGER", "is_active"},
+			{"is_project_manager", "INTEGER", "is_project_manager"},
+			{"use_default_rates", "INTEGER", "use_default_rates"},
+			{"hourly_rate", "NUMERIC", "hourly_rate"},
+			{"budget", "NUMERIC", "budget"},
+		},
+	},
+	{
+		name:     "task_assignments",
+		resource: SyncTaskAssignments,
+		columns: []mirrorColumn{
+			{"project_id", "INTEGER", "project.id"},
+			{"task_id", "INTEGER", "task.id"},
+			{"is_active", "INTEGER", "is_active"},
+			{"billable", "INTEGER", "billable"},
+			{"hourly_rate", "NUMERIC", "hourly_rate"},
+			{"budget", "NUMERIC", "budget"},
+		},
+	},
+	{
+		name:       "estimates",
+		resource:   SyncEstimates,
+		dateColumn: "issue_date",
+		columns: []mirrorColumn{
+			{"client_id", "INTEGER", "client.id"},
+			{"creator_id", "INTEGER", "creator.id"},
+			{"number", "TEXT", "number"},
+			{"purchase_order", "TEXT", "purchase_order"},
+			{"amount", "NUMERIC", "amount"},
+			{"tax", "NUMERIC", "tax"},
+			{"tax_amount", "NUMERIC", "tax_amount"},
+			{"tax2", "NUMERIC", "tax2"},
+			{"tax2_amount", "NUMERIC", "tax2_amount"},
+			{"discount", "NUMERIC", "discount"},
+			{"discount_amount", "NUMERIC", "discount_amount"},
+			{"subject", "TEXT", "subject"},
+			{"notes", "TEXT", "notes"},
+			{"currency", "TEXT", "currency"},
+			{"state", "TEXT", "state"},
+			{"issue_date", "TEXT", "issue_date"},
+			{"sent_at", "TEXT", "sent_at"},
+			{"accepted_at", "TEXT", "accepted_at"},
+			{"declined_at", "TEXT", "declined_at"},
+		},
+		children: []mirrorChildTable{
+			{
+				name:         "estimate_line_items",
+				parentColumn: "estimate_id",
+				path:         "line_items",
+				columns: []mirrorColumn{
+					{"id", "INTEGER", "id"},
+					{"kind", "TEXT", "kind"},
+					{"description", "TEXT", "description"},
+					{"quantity", "NUMERIC", "quantity"},
+					{"unit_price", "NUMERIC", "unit_price"},
+					{"amount", "NUMERIC", "amount"},
+					{"taxed", "INTEGER", "taxed"},
+					{"taxed2", "INTEGER", "taxed2"},
+				},
+			},
+		},
+	},
+	{
+		name:       "invoices",
+		resource:   SyncInvoices,
+		dateColumn: "issue_date",
+		columns: []mirrorColumn{
+			{"client_id", "INTEGER", "client.id"},
+			{"creator_id", "INTEGER", "creator.id"},
+			{"estimate_id", "INTEGER", "estimate.id"},
+			{"retainer_id", "INTEGER", "retainer.id"},
+			{"number", "TEXT", "number"},
+			{"purchase_order", "TEXT", "purchase_order"},
+			{"amount", "NUMERIC", "amount"},
+			{"due_amount", "NUMERIC", "due_amount"},
+			{"tax", "NUMERIC", "tax"},
+			{"tax_amount", "NUMERIC", "tax_amount"},
+			{"tax2", "NUMERIC", "tax2"},
+			{"tax2_amount", "NUMERIC", "tax2_amount"},
+			{"discount", "NUMERIC", "discount"},
+			{"discount_amount", "NUMERIC", "discount_amount"},
+			{"subject", "TEXT", "subject"},
+			{"notes", "TEXT", "notes"},
+			{"currency", "TEXT", "currency"},
+			{"state", "TEXT", "state"},
+			{"period_start", "TEXT", "period_start"},
+			{"period_end", "TEXT", "period_end"},
+			{"issue_date", "TEXT", "issue_date"},
+			{"due_date", "TEXT", "due_date"},
+			{"payment_term", "TEXT", "payment_term"},
+			{"sent_at", "TEXT", "sent_at"},
+			{"paid_at", "TEXT", "paid_at"},
+			{"paid_date", "TEXT", "paid_date"},
+			{"closed_at", "TEXT", "closed_at"},
+		},
+		children: []mirrorChildTable{
+			{
+				name:         "invoice_line_items",
+				parentColumn: "invoice_id",
+				path:         "line_items",
+				columns: []mirrorColumn{
+					{"id", "INTEGER", "id"},
+					{"project_id", "INTEGER", "project.id"},
+					{"kind", "TEXT", "kind"},
+					{"description", "TEXT", "description"},
+					{"quantity", "NUMERIC", "quantity"},
+					{"unit_price", "NUMERIC", "unit_price"},
+					{"amount", "NUMERIC", "amount"},
+					{"taxed", "INTEGER", "taxed"},
+					{"taxed2", "INTEGER", "taxed2"},
+				},
+			},
+		},
+	},
+	{
+		name:       "time_entries",
+		resource:   SyncTimeEntries,
+		dateColumn: "spent_date",
+		columns: []mirrorColumn{
+			{"spent_date", "TEXT", "spent_date"},
+			{"user_id", "INTEGER", "user.id"},
+			{"client_id", "INTEGER", "client.id"},
+			{"project_id", "INTEGER", "project.id"},
+			{"task_id", "INTEGER", "task.id"},
+			{"user_assignment_id", "INTEGER", "user_assignment.id"},
+			{"task_assignment_id", "INTEGER", "task_assignment.id"},
+			{"invoice_id", "INTEGER", "invoice.id"},
+			{"hours", "NUMERIC", "hours"},
+			{"hours_without_timer", "NUMERIC", "hours_without_timer"},
+			{"rounded_hours", "NUMERIC", "rounded_hours"},
+			{"notes", "TEXT", "notes"},
+			{"is_locked", "INTEGER", "is_locked"},
+			{"locked_reason", "TEXT", "locked_reason"},
+			{"is_closed", "INTEGER", "is_closed"},
+			{"is_billed", "INTEGER", "is_billed"},
+			{"timer_started_at", "TEXT", "timer_started_at"},
+			{"started_time", "TEXT", "started_time"},
+			{"ended_time", "TEXT", "ended_time"},
+			{"is_running", "INTEGER", "is_running"},
+			{"billable", "INTEGER", "billable"},
+			{"budgeted", "INTEGER", "budgeted"},
+			{"billable_rate", "NUMERIC", "billable_rate"},
+			{"cost_rate", "NUMERIC", "cost_rate"},
+			{"external_reference_id", "TEXT", "external_reference.id"},
+			{"external_reference_group_id", "TEXT", "external_reference.group_id"},
+			{"external_reference_permalink", "TEXT", "external_reference.permalink"},
+		},
+	},
+	{
+		name:       "expenses",
+		resource:   SyncExpenses,
+		dateColumn: "spent_date",
+		columns: []mirrorColumn{
+			{"spent_date", "TEXT", "spent_date"},
+			{"user_id", "INTEGER", "user.id"},
+			{"client_id", "INTEGER", "client.id"},
+			{"project_id", "INTEGER", "project.id"},
+			{"expense_category_id", "INTEGER", "expense_category.id"},
+			{"expense_category_name", "TEXT", "expense_category.name"},
+			{"user_assignment_id", "INTEGER", "user_assignment.id"},
+			{"invoice_id", "INTEGER", "invoice.id"},
+			{"notes", "TEXT", "notes"},
+			{"units", "NUMERIC", "units"},
+			{"total_cost", "NUMERIC", "total_cost"},
+			{"billable", "INTEGER", "billable"},
+			{"is_closed", "INTEGER", "is_closed"},
+			{"is_locked", "INTEGER", "is_locked"},
+			{"is_billed", "INTEGER", "is_billed"},
+			{"locked_reason", "TEXT", "locked_reason"},
+			{"receipt_url", "TEXT", "receipt.url"},
+		},
+	},
+}
+
+// Initializes a new Mirror that writes the data retrieved through client into db.
+// Call Init before the first Refresh.
+func NewMirror(client *HarvestClient, db *sql.DB) *Mirror {
+	return &Mirror{
+		client: client,
+		db:     db,
+	}
+}
+
+// Creates the mirror's tables if they do not exist yet.
+func (m *Mirror) Init() error {
+	statements := []string{
+		"CREATE TABLE IF NOT EXISTS sync_watermarks (resource TEXT PRIMARY KEY, watermark TEXT NOT NULL)",
+	}
+
+	for _, table := range mirrorTables {
+		statements = append(statements, table.createStatements()...)
+	}
+
+	for _, statement := range statements {
+		if _, err := m.db.Exec(statement); err != nil {
+			return fmt.Errorf("%s: %w", statement, err)
+		}
+	}
+
+	return nil
+}
+
+// Pulls every record of the given resources, or of every resource if none are given, that
+// changed since the previous refresh. The first refresh pulls everything.
+func (m *Mirror) Refresh(resources ...SyncResource) error {
+	return NewSyncEngine(m.client, m, m.Apply).Sync(resources...)
+}
+
+// Removes mirrored records of the resource within the from/to window that were deleted in
+// Harvest. See Reconciler.Reconcile.
+func (m *Mirror) Reconcile(resource SyncResource, from, to time.Time) error {
+	return NewReconciler(m.client, m, m.Apply).Reconcile(resource, from, to)
+}
+
+// Writes a single sync event to the database. Suitable as the SyncHandler of a SyncEngine
+// or Reconciler that is driven by the caller.
+func (m *Mirror) Apply(event SyncEvent) error {
+	table, err := findMirrorTable(event.Resource)
+
+	if err != nil {
+		return err
+	}
+
+	tx, err := m.db.Begin()
+
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback()
+
+	if event.Type == SyncEventDeleted {
+		err = table.delete(tx, event.Id)
+	} else {
+		err = table.upsert(tx, event.Data)
+	}
+
+	if err != nil {
+		return fmt.Errorf("%s %d: %w", event.Resource, event.Id, err)
+	}
+
+	return tx.Commit()
+}
+
+func (m *Mirror) GetWatermark(resource SyncResource) (time.Time, error) {
+	var watermark string
+
+	err := m.db.QueryRow("SELECT watermark FROM sync_watermarks WHERE resource = ?", string(resource)).Scan(&watermark)
+
+	if err == sql.ErrNoRows {
+		return time.Time{}, nil
+	}
+
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return time.Parse(time.RFC3339Nano, watermark)
+}
+
+func (m *Mirror) SetWatermark(resource SyncResource, watermark time.Time) error {
+	_, err := m.db.Exec(
+		"INSERT OR REPLACE INTO sync_watermarks (resource, watermark) VALUES (?, ?)",
+		string(resource),
+		watermark.UTC().Format(time.RFC3339Nano),
+	)
+
+	return err
+}
+
+func (m *Mirror) MirroredIds(resource SyncResource, from, to time.Time) ([]uint, error) {
+	table, err := findMirrorTable(resource)
+
+	if err != nil {
+		return nil, err
+	}
+
+	query := fmt.Sprintf("SELECT id FROM %s", table.name)
+	var args []interface{}
+
+	if table.dateColumn != "" && (!from.IsZero() || !to.IsZero()) {
+		var conditions []string
+
+		if !from.IsZero() {
+			conditions = append(conditions, fmt.Sprintf("%s >= ?", table.dateColumn))
+			args = append(args, from.Format("2006-01-02"))
+		}
+
+		if !to.IsZero() {
+			conditions = append(conditions, fmt.Sprintf("%s <= ?", table.dateColumn))
+			args = append(args, to.Format("2006-01-02"))
+		}
+
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	rows, err := m.db.Query(query, args...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var ids []uint
+
+	for rows.Next() {
+		var id uint
+
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
+func findMirrorTable(resource SyncResource) (mirrorTable, error) {
+	for _, table := range mirrorTables {
+		if table.resource == resource {
+			return table, nil
+		}
+	}
+
+	return mirrorTable{}, fmt.Errorf("unsupported mirror resource %q", resource)
+}
+
+func (t mirrorTable) createStatements() []string {
+	columns := []string{"id INTEGER PRIMARY KEY"}
+
+	for _, column := range t.columns {
+		columns = append(columns, fmt.Sprintf("%s %s", column.name, column.sqlType))
+	}
+
+	columns = append(columns, "created_at TEXT", "updated_at TEXT", "data TEXT NOT NULL")
+
+	statements := []string{
+		fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (%s)", t.name, strings.Join(columns, ", ")),
+	}
+
+	if t.dateColumn != "" {
+		statements = append(statements, fmt.Sprintf(
+			"CREATE INDEX IF NOT EXISTS %s_%s ON %s (%s)", t.name, t.dateColumn, t.name, t.dateColumn))
+	}
+
+	for _, child := range t.children {
+		childColumns := []string{fmt.Sprintf("%s INTEGER NOT NULL", child.parentColumn)}
+
+		for _, column := range child.columns {
+			childColumns = append(childColumns, fmt.Sprintf("%s %s", column.name, column.sqlType))
+		}
+
+		statements = append(statements,
+			fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (%s)", child.name, strings.Join(childColumns, ", ")),
+			fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s_%s ON %s (%s)", child.name, child.parentColumn, child.name, child.parentColumn),
+		)
+	}
+
+	return statements
+}
+
+func (t mirrorTable) upsert(tx *sql.Tx, data json.RawMessage) error {
+	var object map[string]interface{}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	if err := decoder.Decode(&object); err != nil {
+		return err
+	}
+
+	names := []string{"id"}
+	values := []interface{}{mirrorValue(object, "id")}
+
+	for _, column := range t.columns {
+		names = append(names, column.name)
+		values = append(values, mirrorValue(object, column.path))
+	}
+
+	names = append(names, "created_at", "updated_at", "data")
+	values = append(values, mirrorValue(object, "created_at"), mirrorValue(object, "updated_at"), string(data))
+
+	_, err := tx.Exec(
+		fmt.Sprintf("INSERT OR REPLACE INTO %s (%s) VALUES (%s)", t.name, strings.Join(names, ", "), placeholders(len(names))),
+		values...,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	for _, child := range t.children {
+		if err := child.replace(tx, values[0], object); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (t mirrorTable) delete(tx *sql.Tx, id uint) error {
+	for _, child := range t.children {
+		if _, err := tx.Exec(fmt.Sprintf("DELETE FROM %s WHERE %s = ?", child.name, child.parentColumn), id); err != nil {
+			return err
+		}
+	}
+
+	_, err := tx.Exec(fmt.Sprintf("DELETE FROM %s WHERE id = ?", t.name), id)
+	return err
+}
+
+func (c mirrorChildTable) replace(tx *sql.Tx, parentId interface{}, parent map[string]interface{}) error {
+	if _, err := tx.Exec(fmt.Sprintf("DELETE FROM %s WHERE %s = ?", c.name, c.parentColumn), parentId); err != nil {
+		return err
+	}
+
+	items, _ := lookupJsonPath(parent, c.path).([]interface{})
+
+	names := []string{c.parentColumn}
+
+	for _, column := range c.columns {
+		names = append(names, column.name)
+	}
+
+	statement := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)", c.name, strings.Join(names, ", "), placeholders(len(names)))
+
+	for _, item := range items {
+		values := []interface{}{parentId}
+
+		for _, column := range c.columns {
+			if column.path == "" {
+				values = append(values, sqlValue(item))
+				continue
+			}
+
+			object, _ := item.(map[string]interface{})
+			values = append(values, mirrorValue(object, column.path))
+		}
+
+		if _, err := tx.Exec(statement, values...); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Reads the value at the dotted JSON path of object as a value the SQL driver accepts.
+func mirrorValue(object map[string]interface{}, path string) interface{} {
+	return sqlValue(lookupJsonPath(object, path))
+}
+
+func lookupJsonPath(object map[string]interface{}, path string) interface{} {
+	var value interface{} = object
+
+	for _, key := range strings.Split(path, ".") {
+		current, ok := value.(map[string]interface{})
+
+		if !ok {
+			return nil
+		}
+
+		value = current[key]
+	}
+
+	return value
+}
+
+func sqlValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case json.Number:
+		return v.String()
+	case map[string]interface{}, []interface{}:
+		b, _ := json.Marshal(v)
+		return string(b)
+	default:
+		return v
+	}
+}
+
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
+}
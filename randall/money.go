@@ -0,0 +1,254 @@
+package randall
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// An amount in a currency.
+type Money struct {
+	Amount   decimal.Decimal
+	Currency Currency
+}
+
+// Provides exchange rates between currencies.
+type RateProvider interface {
+	// Returns the amount of the to currency one unit of the from currency is worth on the
+	// date.
+	Rate(from, to Currency, on time.Time) (decimal.Decimal, error)
+}
+
+// A RateProvider with fixed rates.
+type StaticRates struct {
+	mu    sync.RWMutex
+	rates map[string]decimal.Decimal
+}
+
+// A RateProvider reading dated rates from CSV.
+type CsvRates struct {
+	// The rates of every currency pair, sorted by date. Undated rates have the zero date.
+	rates map[string][]datedRate
+}
+
+type datedRate struct {
+	date time.Time
+	rate decimal.Decimal
+}
+
+func NewMoney(amount decimal.Decimal, currency Currency) Money {
+	return Money{Amount: amount, Currency: currency}
+}
+
+// Returns the sum of both amounts, which must be in the same currency.
+func (m Money) Add(other Money) (Money, error) {
+	if m.Currency != other.Currency {
+		return m, fmt.Errorf("cannot add %s to %s", other.Currency, m.Currency)
+	}
+
+	return Money{Amount: m.Amount.Add(other.Amount), Currency: m.Currency}, nil
+}
+
+// Returns the amount rounded to the minor units of its currency. Amounts in unknown
+// currencies are returned as is.
+func (m Money) Round() Money {
+	if rounded, err := RoundToCurrency(m.Amount, m.Currency); err == nil {
+		m.Amount = rounded
+	}
+
+	return m
+}
+
+// Converts the amount into the currency with the rate of the date. The result is not
+// rounded.
+func (m Money) Convert(currency Currency, rates RateProvider, on time.Time) (Money, error) {
+	if m.Currency == currency {
+		return m, nil
+	}
+
+	rate, err := rates.Rate(m.Currency, currency, on)
+
+	if err != nil {
+		return Money{}, err
+	}
+
+	return Money{Amount: m.Amount.Mul(rate), Currency: currency}, nil
+}
+
+// Formats the amount with the minor units of its currency, e.g. "1234.50 USD".
+func (m Money) String() string {
+	return formatCurrencyAmount(m.Amount, m.Currency) + " " + string(m.Currency)
+}
+
+func NewStaticRates() *StaticRates {
+	return &StaticRates{
+		rates: make(map[string]decimal.Decimal),
+	}
+}
+
+// Sets the amount of the to currency one unit of the from currency is worth.
+func (r *StaticRates) Set(from, to Currency, rate decimal.Decimal) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.rates[ratePair(from, to)] = rate
+}
+
+// Returns the rate set for the currency pair, or the inverse of the rate set for the
+// reverse pair. The date is ignored.
+func (r *StaticRates) Rate(from, to Currency, on time.Time) (decimal.Decimal, error) {
+	if from == to {
+		return decimal.NewFromInt(1), nil
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if rate, ok := r.rates[ratePair(from, to)]; ok {
+		return rate, nil
+	}
+
+	if rate, ok := r.rates[ratePair(to, from)]; ok && !rate.IsZero() {
+		return decimal.NewFromInt(1).Div(rate), nil
+	}
+
+	return decimal.Zero, fmt.Errorf("no exchange rate from %s to %s", from, to)
+}
+
+// Reads exchange rates from CSV with a header row and the columns from, to and rate, and
+// an optional date column (YYYY-MM-DD). Rates apply from their date until the next rate
+// of the pair, undated rates apply before the first dated one.
+func ReadCsvRates(r io.Reader) (*CsvRates, error) {
+	cr := csv.NewReader(r)
+	cr.TrimLeadingSpace = true
+
+	header, err := cr.Read()
+
+	if err != nil {
+		return nil, fmt.Errorf("reading exchange rate header: %w", err)
+	}
+
+	columns := make(map[string]int, len(header))
+
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+
+	for _, name := range []string{"from", "to", "rate"} {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("exchange rates are missing the %s column", name)
+		}
+	}
+
+	rates := &CsvRates{rates: make(map[string][]datedRate)}
+
+	for line := 2; ; line++ {
+		record, err := cr.Read()
+
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		rate, err := decimal.NewFromString(strings.TrimSpace(record[columns["rate"]]))
+
+		if err != nil || !rate.IsPositive() {
+			return nil, fmt.Errorf("line %d: invalid rate %q", line, record[columns["rate"]])
+		}
+
+		var date time.Time
+
+		if i, ok := columns["date"]; ok && strings.TrimSpace(record[i]) != "" {
+			if date, err = time.Parse("2006-01-02", strings.TrimSpace(record[i])); err != nil {
+				return nil, fmt.Errorf("line %d: invalid date %q", line, record[i])
+			}
+		}
+
+		from, err := ParseCurrency(record[columns["from"]])
+
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+
+		to, err := ParseCurrency(record[columns["to"]])
+
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+
+		pair := ratePair(from, to)
+		rates.rates[pair] = append(rates.rates[pair], datedRate{date: date, rate: rate})
+	}
+
+	for _, pairRates := range rates.rates {
+		sort.SliceStable(pairRates, func(i, j int) bool {
+			return pairRates[i].date.Before(pairRates[j].date)
+		})
+	}
+
+	return rates, nil
+}
+
+// Reads exchange rates from the CSV file at path, see ReadCsvRates.
+func ReadCsvRatesFile(path string) (*CsvRates, error) {
+	f, err := os.Open(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer f.Close()
+	return ReadCsvRates(f)
+}
+
+// Returns the latest rate of the currency pair dated on or before the date, or the inverse
+// of the reverse pair's.
+func (r *CsvRates) Rate(from, to Currency, on time.Time) (decimal.Decimal, error) {
+	if from == to {
+		return decimal.NewFromInt(1), nil
+	}
+
+	if rate, ok := r.rateOn(ratePair(from, to), on); ok {
+		return rate, nil
+	}
+
+	if rate, ok := r.rateOn(ratePair(to, from), on); ok {
+		return decimal.NewFromInt(1).Div(rate), nil
+	}
+
+	return decimal.Zero, fmt.Errorf("no exchange rate from %s to %s on %s", from, to, on.Format("2006-01-02"))
+}
+
+func (r *CsvRates) rateOn(pair string, on time.Time) (decimal.Decimal, bool) {
+	day := time.Date(on.Year(), on.Month(), on.Day(), 0, 0, 0, 0, time.UTC)
+	var found *datedRate
+
+	for i, rate := range r.rates[pair] {
+		if rate.date.After(day) {
+			break
+		}
+
+		found = &r.rates[pair][i]
+	}
+
+	if found == nil {
+		return decimal.Zero, false
+	}
+
+	return found.rate, true
+}
+
+func ratePair(from, to Currency) string {
+	return strings.ToUpper(string(from)) + "/" + strings.ToUpper(string(to))
+}
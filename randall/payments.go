@@ -0,0 +1,373 @@
+package randall
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// An incoming payment from a bank statement.
+type BankTransaction struct {
+	// The id of the transaction at the bank, recorded as the payment's notes.
+	Id     string
+	Amount decimal.Decimal
+	Date   time.Time
+	// The remittance information, e.g. "Invoice 2024-017".
+	Reference string
+	// The ISO 4217 code of the currency. Matches invoices of any currency when empty.
+	Currency Currency
+	// The name of the payer, used to narrow down invoices matched by amount.
+	Payer string
+}
+
+// The outcome of reconciling a bank transaction.
+type PaymentMatchStatus string
+
+const (
+	// The transaction pays the remaining due amount of the invoice.
+	PaymentMatched PaymentMatchStatus = "matched"
+	// The transaction pays part of the remaining due amount of the invoice.
+	PaymentPartial PaymentMatchStatus = "partial"
+	// The transaction exceeds the remaining due amount of the invoice. Only the due amount
+	// is recorded.
+	PaymentOverpaid PaymentMatchStatus = "overpaid"
+	// No open invoice matches the transaction.
+	PaymentUnmatched PaymentMatchStatus = "unmatched"
+	// Several open invoices match the transaction equally well.
+	PaymentAmbiguous PaymentMatchStatus = "ambiguous"
+	// The invoice was matched but recording the payment failed.
+	PaymentFailed PaymentMatchStatus = "failed"
+	// A payment of the transaction was recorded by an earlier run.
+	PaymentAlreadyRecorded PaymentMatchStatus = "already recorded"
+)
+
+// Starts the notes of payments recorded for a bank transaction, followed by its id.
+const bankTransactionNotesPrefix = "Bank transaction "
+
+// A bank transaction and the invoice it was matched to.
+type PaymentMatch struct {
+	Transaction BankTransaction
+	// The matched invoice, or the invoice the transaction was recorded for by an earlier run.
+	// Nil if the transaction is unmatched or ambiguous.
+	Invoice *Invoice
+	Status  PaymentMatchStatus
+	// The amount recorded as payment.
+	Amount decimal.Decimal
+	// The amount exceeding the invoice's remaining due amount.
+	Overpayment decimal.Decimal
+	// Why the transaction is unmatched or ambiguous.
+	Reason string
+	Err    error
+}
+
+// The result of reconciling bank transactions with open invoices.
+type PaymentReconciliationReport struct {
+	DryRun  bool
+	Matches []PaymentMatch
+}
+
+// Matches bank transactions to open invoices and records them as invoice payments.
+type PaymentReconciler struct {
+	client *HarvestClient
+	// Matches transactions without recording payments.
+	DryRun bool
+}
+
+func NewPaymentReconciler(client *HarvestClient) *PaymentReconciler {
+	return &PaymentReconciler{client: client}
+}
+
+// Matches every transaction to an open invoice and records a payment for it. Transactions
+// are matched to the invoice whose number appears in their reference, or else to the only
+// invoice whose remaining due amount equals the transaction's amount, narrowed down by the
+// payer's name if several do. Transactions are processed by date, so several partial
+// payments of the same invoice are recorded in order. Transactions with an id recorded on
+// any invoice by an earlier run are skipped, so a statement can be reconciled again.
+func (r *PaymentReconciler) Reconcile(transactions []BankTransaction) (PaymentReconciliationReport, error) {
+	report := PaymentReconciliationReport{DryRun: r.DryRun}
+
+	invoices, err := r.client.Invoices.GetAllPages(HarvestCollectionParams{State: string(InvoiceStateOpen)})
+
+	if err != nil {
+		return report, err
+	}
+
+	recorded, err := r.recordedTransactions(transactions)
+
+	if err != nil {
+		return report, err
+	}
+
+	// The due amount of every invoice left after the payments recorded so far
+	due := make(map[uint]decimal.Decimal, len(invoices))
+	numbers := make(map[uint]*regexp.Regexp, len(invoices))
+
+	for _, invoice := range invoices {
+		due[invoice.Id] = invoice.DueAmount
+
+		if invoice.Number != "" {
+			numbers[invoice.Id] = invoiceNumberPattern(invoice.Number)
+		}
+	}
+
+	sorted := make([]BankTransaction, len(transactions))
+	copy(sorted, transactions)
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Date.Before(sorted[j].Date)
+	})
+
+	for _, tx := range sorted {
+		if invoice, ok := recorded[tx.Id]; ok && tx.Id != "" {
+			report.Matches = append(report.Matches, PaymentMatch{
+				Transaction: tx,
+				Invoice:     invoice,
+				Status:      PaymentAlreadyRecorded,
+				Reason:      fmt.Sprintf("recorded on invoice %s", invoice.Number),
+			})
+			continue
+		}
+
+		match := matchPayment(tx, invoices, due, numbers)
+
+		if match.Invoice != nil {
+			remaining := due[match.Invoice.Id]
+			match.Amount = tx.Amount
+			match.Status = PaymentMatched
+
+			switch tx.Amount.Cmp(remaining) {
+			case -1:
+				match.Status = PaymentPartial
+			case 1:
+				match.Status = PaymentOverpaid
+				match.Amount = remaining
+				match.Overpayment = tx.Amount.Sub(remaining)
+			}
+
+			// Nothing is left to record on invoices paid in full earlier in the run
+			if !r.DryRun && match.Amount.IsPositive() {
+				match.Err = r.record(match)
+
+				if match.Err != nil {
+					match.Status = PaymentFailed
+				}
+			}
+
+			if match.Err == nil {
+				due[match.Invoice.Id] = remaining.Sub(match.Amount)
+			}
+		}
+
+		report.Matches = append(report.Matches, match)
+	}
+
+	return report, nil
+}
+
+// Returns the invoices of the bank transactions whose payments were recorded by earlier
+// runs, by transaction id. Recording a payment updates its invoice, so only invoices in any
+// state updated since the earliest transaction that were paid at least partially can have
+// such payments.
+func (r *PaymentReconciler) recordedTransactions(transactions []BankTransaction) (map[string]*Invoice, error) {
+	recorded := make(map[string]*Invoice)
+
+	if len(transactions) == 0 {
+		return recorded, nil
+	}
+
+	earliest := transactions[0].Date
+
+	for _, tx := range transactions[1:] {
+		if tx.Date.Before(earliest) {
+			earliest = tx.Date
+		}
+	}
+
+	invoices, err := r.client.Invoices.GetAllPages(HarvestCollectionParams{UpdatedSince: earliest})
+
+	if err != nil {
+		return nil, err
+	}
+
+	for i, invoice := range invoices {
+		if invoice.State == InvoiceStateDraft || invoice.DueAmount.Equal(invoice.Amount) {
+			continue
+		}
+
+		payments, err := r.client.Invoices.GetAllInvoicePaymentPages(invoice.Id)
+
+		if err != nil {
+			return nil, err
+		}
+
+		for _, payment := range payments {
+			if id, ok := paymentTransactionId(payment.Notes); ok {
+				recorded[id] = &invoices[i]
+			}
+		}
+	}
+
+	return recorded, nil
+}
+
+// Returns the transactions that could not be matched to a single invoice.
+func (r PaymentReconciliationReport) Unmatched() []PaymentMatch {
+	return r.filter(PaymentUnmatched, PaymentAmbiguous)
+}
+
+// Returns the transactions exceeding the due amount of their invoice.
+func (r PaymentReconciliationReport) Overpaid() []PaymentMatch {
+	return r.filter(PaymentOverpaid)
+}
+
+// Returns the transactions whose payment could not be recorded.
+func (r PaymentReconciliationReport) Failed() []PaymentMatch {
+	return r.filter(PaymentFailed)
+}
+
+func (r PaymentReconciliationReport) filter(statuses ...PaymentMatchStatus) []PaymentMatch {
+	var matches []PaymentMatch
+
+	for _, m := range r.Matches {
+		for _, status := range statuses {
+			if m.Status == status {
+				matches = append(matches, m)
+				break
+			}
+		}
+	}
+
+	return matches
+}
+
+func (r *PaymentReconciler) record(match PaymentMatch) error {
+	tx := match.Transaction
+	notes := strings.TrimSpace(tx.Reference)
+
+	if tx.Id != "" {
+		notes = strings.TrimSpace(fmt.Sprintf("%s%s: %s", bankTransactionNotesPrefix, tx.Id, tx.Reference))
+	}
+
+	req := CreateInvoicePaymentRequest{
+		Amount:   match.Amount,
+		PaidDate: OptionalTime(time.Date(tx.Date.Year(), tx.Date.Month(), tx.Date.Day(), 0, 0, 0, 0, time.UTC)),
+	}
+
+	if notes != "" {
+		req.Notes = OptionalString(notes)
+	}
+
+	resp, err := r.client.Invoices.CreateInvoicePayment(match.Invoice.Id, req)
+
+	if err != nil {
+		return err
+	}
+
+	return checkResponse(resp)
+}
+
+// Matches the transaction to an invoice. numbers holds the pattern of every invoice's number.
+func matchPayment(tx BankTransaction, invoices []Invoice, due map[uint]decimal.Decimal, numbers map[uint]*regexp.Regexp) PaymentMatch {
+	match := PaymentMatch{Transaction: tx, Status: PaymentUnmatched}
+
+	if !tx.Amount.IsPositive() {
+		match.Reason = "not an incoming payment"
+		return match
+	}
+
+	var candidates []int
+
+	for i, invoice := range invoices {
+		if tx.Currency == "" || strings.EqualFold(string(invoice.Currency), string(tx.Currency)) {
+			candidates = append(candidates, i)
+		}
+	}
+
+	// Prefer the longest invoice number found in the reference, so "2024-1" doesn't
+	// shadow "2024-17". Invoices paid in full earlier in the run are matched too, so
+	// further payments of them are reported as overpaid.
+	var byNumber *Invoice
+
+	for _, i := range candidates {
+		pattern, ok := numbers[invoices[i].Id]
+
+		if !ok || !pattern.MatchString(tx.Reference) {
+			continue
+		}
+
+		number := invoices[i].Number
+
+		if byNumber == nil || len(number) > len(byNumber.Number) {
+			byNumber = &invoices[i]
+		}
+	}
+
+	if byNumber != nil {
+		match.Invoice = byNumber
+		return match
+	}
+
+	var byAmount []*Invoice
+
+	for _, i := range candidates {
+		if due[invoices[i].Id].IsPositive() && due[invoices[i].Id].Equal(tx.Amount) {
+			byAmount = append(byAmount, &invoices[i])
+		}
+	}
+
+	if len(byAmount) > 1 && tx.Payer != "" {
+		var byPayer []*Invoice
+
+		for _, invoice := range byAmount {
+			if namesMatch(tx.Payer, invoice.Client.Name) {
+				byPayer = append(byPayer, invoice)
+			}
+		}
+
+		if len(byPayer) > 0 {
+			byAmount = byPayer
+		}
+	}
+
+	switch len(byAmount) {
+	case 0:
+		match.Reason = "no open invoice is referenced or due the amount"
+	case 1:
+		match.Invoice = byAmount[0]
+	default:
+		match.Status = PaymentAmbiguous
+		ambiguous := make([]string, len(byAmount))
+
+		for i, invoice := range byAmount {
+			ambiguous[i] = invoice.Number
+		}
+
+		match.Reason = "the amount is due on invoices " + strings.Join(ambiguous, ", ")
+	}
+
+	return match
+}
+
+// Returns an expression matching the invoice number as a whole word.
+func invoiceNumberPattern(number string) *regexp.Regexp {
+	return regexp.MustCompile(`(?i)(^|[^\pL\pN])` + regexp.QuoteMeta(number) + `($|[^\pL\pN])`)
+}
+
+// Returns the id of the bank transaction a payment was recorded for, from its notes.
+func paymentTransactionId(notes string) (string, bool) {
+	if !strings.HasPrefix(notes, bankTransactionNotesPrefix) {
+		return "", false
+	}
+
+	id, _, _ := strings.Cut(strings.TrimPrefix(notes, bankTransactionNotesPrefix), ":")
+	return id, id != ""
+}
+
+func namesMatch(payer, client string) bool {
+	payer, client = strings.ToLower(payer), strings.ToLower(client)
+	return strings.Contains(payer, client) || strings.Contains(client, payer)
+}
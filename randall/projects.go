@@ -7,26 +7,43 @@ import (
 	"github.com/shopspring/decimal"
 )
 
+// How the billable amount of a project is calculated.
+type BillBy string
+
+// How the budget of a project is tracked.
+type BudgetBy string
+
 const (
-	ProjectBilledByProject = "Project"
-	ProjectBilledByTask    = "Tasks"
-	ProjectBilledByPeople  = "People"
-	ProjectBilledByNone    = "none"
-
-	ProjectBudgetByHoursPerProject  = "project"
-	ProjectBudgetByTotalProjectFees = "project_cost"
-	ProjectBudgetByHrsPerTask       = "task"
-	ProjectBudgetByFeesPerTask      = "task_fees"
-	ProjectBudgetByHrsPerPerson     = "person"
-	ProjectBudgetByNone             = "none"
+	ProjectBilledByProject BillBy = "Project"
+	ProjectBilledByTask    BillBy = "Tasks"
+	ProjectBilledByPeople  BillBy = "People"
+	ProjectBilledByNone    BillBy = "none"
+
+	ProjectBudgetByHoursPerProject  BudgetBy = "project"
+	ProjectBudgetByTotalProjectFees BudgetBy = "project_cost"
+	ProjectBudgetByHrsPerTask       BudgetBy = "task"
+	ProjectBudgetByFeesPerTask      BudgetBy = "task_fees"
+	ProjectBudgetByHrsPerPerson     BudgetBy = "person"
+	ProjectBudgetByNone             BudgetBy = "none"
 )
 
+var billByValues = []BillBy{ProjectBilledByProject, ProjectBilledByTask, ProjectBilledByPeople, ProjectBilledByNone}
+
+var budgetByValues = []BudgetBy{
+	ProjectBudgetByHoursPerProject,
+	ProjectBudgetByTotalProjectFees,
+	ProjectBudgetByHrsPerTask,
+	ProjectBudgetByFeesPerTask,
+	ProjectBudgetByHrsPerPerson,
+	ProjectBudgetByNone,
+}
+
 type CreateProjectRequest struct {
 	ClientId                         uint             `json:"client_id"`
 	Name                             string           `json:"name"`
 	IsBillable                       bool             `json:"is_billable"`
-	BillBy                           string           `json:"bill_by"`
-	BudgetBy                         string           `json:"budget_by"`
+	BillBy                           BillBy           `json:"bill_by"`
+	BudgetBy                         BudgetBy         `json:"budget_by"`
 	Code                             *string          `json:"code,omitempty"`
 	IsActive                         *bool            `json:"is_active,omitempty"`
 	IsFixedFee                       *bool            `json:"is_fixed_fee,omitempty"`
@@ -48,8 +65,8 @@ type UpdateProjectRequest struct {
 	ClientId                         *uint            `json:"client_id,omitempty"`
 	Name                             *string          `json:"name,omitempty"`
 	IsBillable                       *bool            `json:"is_billable,omitempty"`
-	BillBy                           *string          `json:"bill_by,omitempty"`
-	BudgetBy                         *string          `json:"budget_by,omitempty"`
+	BillBy                           *BillBy          `json:"bill_by,omitempty"`
+	BudgetBy                         *BudgetBy        `json:"budget_by,omitempty"`
 	Code                             *string          `json:"code,omitempty"`
 	IsActive                         *bool            `json:"is_active,omitempty"`
 	IsFixedFee                       *bool            `json:"is_fixed_fee,omitempty"`
@@ -100,12 +117,119 @@ type PatchTaskAssignmentRequest struct {
 	Budget     *decimal.Decimal `json:"budget,omitempty"`
 }
 
+// A minimal representation of a project embedded in a Harvest payload.
+type ProjectRef struct {
+	Id   uint   `json:"id"`
+	Name string `json:"name"`
+	Code string `json:"code"`
+}
+
+// A project as returned by the Harvest API.
+type Project struct {
+	Id                               uint             `json:"id"`
+	Client                           ClientRef        `json:"client"`
+	Name                             string           `json:"name"`
+	Code                             string           `json:"code"`
+	IsActive                         bool             `json:"is_active"`
+	IsBillable                       bool             `json:"is_billable"`
+	IsFixedFee                       bool             `json:"is_fixed_fee"`
+	BillBy                           BillBy           `json:"bill_by"`
+	HourlyRate                       *decimal.Decimal `json:"hourly_rate"`
+	Budget                           *decimal.Decimal `json:"budget"`
+	BudgetBy                         BudgetBy         `json:"budget_by"`
+	BudgetIsMonthly                  bool             `json:"budget_is_monthly"`
+	NotifyWhenOverBudget             bool             `json:"notify_when_over_budget"`
+	OverBudgetNotificationPercentage *decimal.Decimal `json:"over_budget_notification_percentage"`
+	ShowBudgetToAll                  bool             `json:"show_budget_to_all"`
+	CostBudget                       *decimal.Decimal `json:"cost_budget"`
+	CostBudgetIncludeExpenses        bool             `json:"cost_budget_include_expenses"`
+	Fee                              *decimal.Decimal `json:"fee"`
+	Notes                            string           `json:"notes"`
+	StartsOn                         HarvestDate      `json:"starts_on"`
+	EndsOn                           HarvestDate      `json:"ends_on"`
+	CreatedAt                        time.Time        `json:"created_at"`
+	UpdatedAt                        time.Time        `json:"updated_at"`
+}
+
+// A user assignment as returned by the Harvest API.
+type UserAssignment struct {
+	Id               uint             `json:"id"`
+	Project          ProjectRef       `json:"project"`
+	User             ObjectRef        `json:"user"`
+	IsActive         bool             `json:"is_active"`
+	IsProjectManager bool             `json:"is_project_manager"`
+	UseDefaultRates  bool             `json:"use_default_rates"`
+	HourlyRate       *decimal.Decimal `json:"hourly_rate"`
+	Budget           *decimal.Decimal `json:"budget"`
+	CreatedAt        time.Time        `json:"created_at"`
+	UpdatedAt        time.Time        `json:"updated_at"`
+}
+
+// A task assignment as returned by the Harvest API.
+type TaskAssignment struct {
+	Id         uint             `json:"id"`
+	Project    ProjectRef       `json:"project"`
+	Task       ObjectRef        `json:"task"`
+	IsActive   bool             `json:"is_active"`
+	Billable   bool             `json:"billable"`
+	HourlyRate *decimal.Decimal `json:"hourly_rate"`
+	Budget     *decimal.Decimal `json:"budget"`
+	CreatedAt  time.Time        `json:"created_at"`
+	UpdatedAt  time.Time        `json:"updated_at"`
+}
+
 // Encapsulates the Harvest API methods under /projects
 type ProjectsApi struct {
 	baseUrl string
 	client  *internalClient
 }
 
+// Parses how a project is billed, ignoring case.
+func ParseBillBy(s string) (BillBy, error) {
+	return parseEnum("bill by", billByValues, s)
+}
+
+// Returns whether Harvest knows how to bill a project this way.
+func (b BillBy) Valid() bool {
+	return containsEnum(billByValues, b)
+}
+
+func (b BillBy) String() string {
+	return string(b)
+}
+
+func (b BillBy) MarshalJSON() ([]byte, error) {
+	return marshalEnum(b)
+}
+
+func (b *BillBy) UnmarshalJSON(data []byte) (err error) {
+	*b, err = unmarshalEnum(data, ParseBillBy)
+	return err
+}
+
+// Parses how the budget of a project is tracked, ignoring case.
+func ParseBudgetBy(s string) (BudgetBy, error) {
+	return parseEnum("budget by", budgetByValues, s)
+}
+
+// Returns whether Harvest knows how to track a budget this way.
+func (b BudgetBy) Valid() bool {
+	return containsEnum(budgetByValues, b)
+}
+
+func (b BudgetBy) String() string {
+	return string(b)
+}
+
+func (b BudgetBy) MarshalJSON() ([]byte, error) {
+	return marshalEnum(b)
+}
+
+func (b *BudgetBy) UnmarshalJSON(data []byte) (err error) {
+	*b, err = unmarshalEnum(data, ParseBudgetBy)
+	return err
+}
+
 func newProjectsV2(client *internalClient) ProjectsApi {
 	return ProjectsApi{
 		baseUrl: "v2/projects",
@@ -118,6 +242,11 @@ func (api ProjectsApi) GetAll(params ...HarvestCollectionParams) (HarvestRespons
 	return api.client.doGet(api.baseUrl, getOptionalCollectionParams(params))
 }
 
+// Retrieves every page of projects matching params as typed Project objects.
+func (api ProjectsApi) GetAllPages(params ...HarvestCollectionParams) ([]Project, error) {
+	return getAllCollectionPages[Project]("projects", params, api.GetAll)
+}
+
 // Retrieves a Project with the given ProjectID.
 func (api ProjectsApi) Get(projectId uint) (HarvestResponse, error) {
 	return api.client.doGet(fmt.Sprintf("%s/%d", api.baseUrl, projectId))
@@ -139,10 +268,22 @@ func (api ProjectsApi) GetAllUserAssigments(params ...HarvestCollectionParams) (
 	return api.client.doGet("v2/user_assignments", getOptionalCollectionParams(params))
 }
 
+// Retrieves every page of user assignments matching params as typed UserAssignment objects.
+func (api ProjectsApi) GetAllUserAssignmentPages(params ...HarvestCollectionParams) ([]UserAssignment, error) {
+	return getAllCollectionPages[UserAssignment]("user_assignments", params, api.GetAllUserAssigments)
+}
+
 func (api ProjectsApi) GetAllUserAssigmentsForProject(projectId uint, params ...HarvestCollectionParams) (HarvestResponse, error) {
 	return api.client.doGet(fmt.Sprintf("%s/%d/user_assignments", api.baseUrl, projectId), getOptionalCollectionParams(params))
 }
 
+// Retrieves every page of the user assignments of a project as typed UserAssignment objects.
+func (api ProjectsApi) GetAllUserAssignmentPagesForProject(projectId uint, params ...HarvestCollectionParams) ([]UserAssignment, error) {
+	return getAllCollectionPages[UserAssignment]("user_assignments", params, func(params ...HarvestCollectionParams) (HarvestResponse, error) {
+		return api.GetAllUserAssigmentsForProject(projectId, params...)
+	})
+}
+
 func (api ProjectsApi) GetUserAssigment(projectId, userAssignmentId uint) (HarvestResponse, error) {
 	return api.client.doGet(fmt.Sprintf("%s/%d/user_assignments/%d", api.baseUrl, projectId, userAssignmentId))
 }
@@ -163,10 +304,22 @@ func (api ProjectsApi) GetAllTaskAssigments(params ...HarvestCollectionParams) (
 	return api.client.doGet("v2/task_assignments", getOptionalCollectionParams(params))
 }
 
+// Retrieves every page of task assignments matching params as typed TaskAssignment objects.
+func (api ProjectsApi) GetAllTaskAssignmentPages(params ...HarvestCollectionParams) ([]TaskAssignment, error) {
+	return getAllCollectionPages[TaskAssignment]("task_assignments", params, api.GetAllTaskAssigments)
+}
+
 func (api ProjectsApi) GetAllTaskAssigmentsForProject(projectId uint, params ...HarvestCollectionParams) (HarvestResponse, error) {
 	return api.client.doGet(fmt.Sprintf("%s/%d/task_assignments", api.baseUrl, projectId), getOptionalCollectionParams(params))
 }
 
+// Retrieves every page of the task assignments of a project as typed TaskAssignment objects.
+func (api ProjectsApi) GetAllTaskAssignmentPagesForProject(projectId uint, params ...HarvestCollectionParams) ([]TaskAssignment, error) {
+	return getAllCollectionPages[TaskAssignment]("task_assignments", params, func(params ...HarvestCollectionParams) (HarvestResponse, error) {
+		return api.GetAllTaskAssigmentsForProject(projectId, params...)
+	})
+}
+
 func (api ProjectsApi) GetTaskAssigment(projectId, taskAssignmentId uint) (HarvestResponse, error) {
 	return api.client.doGet(fmt.Sprintf("%s/%d/task_assignments/%d", api.baseUrl, projectId, taskAssignmentId))
 }
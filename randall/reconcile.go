@@ -0,0 +1,197 @@
+package randall
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+)
+
+// Provides the IDs of the Harvest records mirrored by a downstream system.
+type MirrorIndex interface {
+	// Returns the IDs of the mirrored records of the resource. When from and to are set, only
+	// the IDs of records dated within the window are returned, using the same date Harvest
+	// filters the resource by (spent_date for time entries and expenses, issue_date for
+	// invoices and estimates).
+	MirroredIds(resource SyncResource, from, to time.Time) ([]uint, error)
+}
+
+// Adapts a function to the MirrorIndex interface.
+type MirrorIndexFunc func(resource SyncResource, from, to time.Time) ([]uint, error)
+
+// Detects records deleted in Harvest by comparing the IDs Harvest still has against
+// the IDs of a MirrorIndex. Polling by updated_since never reports deletions.
+type Reconciler struct {
+	// How long to wait between page requests, to stay within Harvest's rate limits.
+	PageDelay time.Duration
+
+	client  *HarvestClient
+	index   MirrorIndex
+	handler SyncHandler
+}
+
+// The resources Harvest can filter by a from/to date window.
+var datedSyncResources = map[SyncResource]bool{
+	SyncTimeEntries: true,
+	SyncExpenses:    true,
+	SyncInvoices:    true,
+	SyncEstimates:   true,
+}
+
+type syncId struct {
+	Id uint `json:"id"`
+}
+
+func (f MirrorIndexFunc) MirroredIds(resource SyncResource, from, to time.Time) ([]uint, error) {
+	return f(resource, from, to)
+}
+
+// Initializes a new Reconciler. A SyncEventDeleted event is passed to handler for every
+// mirrored record that no longer exists in Harvest.
+func NewReconciler(client *HarvestClient, index MirrorIndex, handler SyncHandler) *Reconciler {
+	return &Reconciler{
+		client:  client,
+		index:   index,
+		handler: handler,
+	}
+}
+
+// Sweeps the IDs of the resource within the from/to window and emits a deleted event for every
+// mirrored ID Harvest no longer returns. Pass zero times to sweep the entire resource, which is
+// required for resources Harvest cannot filter by date. Within a window, every missing ID is
+// fetched directly before it is reported deleted. Nothing is emitted if the sweep fails.
+func (r *Reconciler) Reconcile(resource SyncResource, from, to time.Time) error {
+	windowed := !from.IsZero() || !to.IsZero()
+
+	if windowed && !datedSyncResources[resource] {
+		return fmt.Errorf("%s cannot be reconciled within a date window", resource)
+	}
+
+	mirrored, err := r.index.MirroredIds(resource, from, to)
+
+	if err != nil {
+		return err
+	}
+
+	if len(mirrored) == 0 {
+		return nil
+	}
+
+	params := HarvestCollectionParams{
+		PerPage: OptionalInt(2000),
+		From:    from,
+		To:      to,
+	}
+
+	existing, err := fetchSyncResource[syncId](r.client, resource, params, r.PageDelay)
+
+	if err != nil {
+		return fmt.Errorf("reconciling %s: %w", resource, err)
+	}
+
+	live := make(map[uint]bool, len(existing))
+
+	for _, record := range existing {
+		live[record.Id] = true
+	}
+
+	sort.Slice(mirrored, func(i, j int) bool {
+		return mirrored[i] < mirrored[j]
+	})
+
+	// Every deletion is confirmed before any is emitted, so a failing sweep emits nothing
+	var deleted []uint
+
+	for _, id := range mirrored {
+		if live[id] {
+			continue
+		}
+
+		// A record whose date moved out of the window is missing from the sweep but still
+		// dated within it in the mirror, so confirm it is gone before reporting it deleted
+		if windowed {
+			found, err := r.exists(resource, id)
+
+			if err != nil {
+				return fmt.Errorf("reconciling %s %d: %w", resource, id, err)
+			}
+
+			if found {
+				continue
+			}
+		}
+
+		deleted = append(deleted, id)
+	}
+
+	for _, id := range deleted {
+		err := r.handler(SyncEvent{
+			Type:     SyncEventDeleted,
+			Resource: resource,
+			Id:       id,
+		})
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Returns whether the record of the dated resource still exists in Harvest, fetching it
+// directly after waiting PageDelay.
+func (r *Reconciler) exists(resource SyncResource, id uint) (bool, error) {
+	var get func(id uint) (HarvestResponse, error)
+
+	switch resource {
+	case SyncTimeEntries:
+		get = r.client.TimeEntries.GetTimeEntry
+	case SyncExpenses:
+		get = r.client.Expenses.Get
+	case SyncInvoices:
+		get = r.client.Invoices.Get
+	case SyncEstimates:
+		get = r.client.Estimates.Get
+	default:
+		return false, fmt.Errorf("unsupported dated sync resource %q", resource)
+	}
+
+	if r.PageDelay > 0 {
+		time.Sleep(r.PageDelay)
+	}
+
+	resp, err := get(id)
+
+	if err != nil {
+		return false, err
+	}
+
+	if resp.StatusCode == http.StatusNotFound {
+		return false, nil
+	}
+
+	return true, resp.Err()
+}
+
+// Reconciles the resource across consecutive windows of the given number of days between from
+// and to, so each sweep stays small. Useful for periodically reconciling long date ranges.
+func (r *Reconciler) ReconcileByWindow(resource SyncResource, from, to time.Time, days int) error {
+	if days < 1 {
+		return fmt.Errorf("window must span at least one day, got %d", days)
+	}
+
+	for start := from; !start.After(to); start = start.AddDate(0, 0, days) {
+		end := start.AddDate(0, 0, days-1)
+
+		if end.After(to) {
+			end = to
+		}
+
+		if err := r.Reconcile(resource, start, end); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -2,6 +2,7 @@ package randall
 
 import (
 	"fmt"
+	"time"
 )
 
 // Encapsulates the Harvest API methods under /roles.
@@ -24,6 +25,15 @@ type UpdateRoleRequest struct {
 	UserIds []uint `json:"user_ids,omitempty"`
 }
 
+// A role as returned by the Harvest API.
+type Role struct {
+	Id        uint      `json:"id"`
+	Name      string    `json:"name"`
+	UserIds   []uint    `json:"user_ids"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
 func newRolesV2(client *internalClient) RolesApi {
 	return RolesApi{
 		baseUrl: "v2/roles",
@@ -36,6 +46,11 @@ func (api RolesApi) GetAllRoles(params ...HarvestCollectionParams) (HarvestRespo
 	return api.client.doGet(api.baseUrl, getOptionalCollectionParams(params))
 }
 
+// Retrieves every page of roles matching params as typed Role objects.
+func (api RolesApi) GetAllPages(params ...HarvestCollectionParams) ([]Role, error) {
+	return getAllCollectionPages[Role]("roles", params, api.GetAllRoles)
+}
+
 // Retrieves a Role with the given RoleID.
 func (api RolesApi) GetRole(roleId uint) (HarvestResponse, error) {
 	return api.client.doGet(fmt.Sprintf("%s/%d", api.baseUrl, roleId))
@@ -0,0 +1,215 @@
+package randall
+
+import (
+	"encoding/csv"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Rounds the hours of time entries, e.g. up to 6 minute increments.
+type RoundingPolicy interface {
+	RoundHours(hours decimal.Decimal) decimal.Decimal
+}
+
+// A RoundingPolicy implemented by a function.
+type RoundingPolicyFunc func(hours decimal.Decimal) decimal.Decimal
+
+// The raw and rounded hours of a client's time entries and the billing impact of rounding.
+type RoundingReportLine struct {
+	Client  ClientRef
+	Entries int
+	// The hours as tracked.
+	RawHours decimal.Decimal
+	// The hours rounded entry by entry.
+	RoundedHours decimal.Decimal
+	// The billable amount of the tracked hours, in the client's currency.
+	RawAmount decimal.Decimal
+	// The billable amount of the rounded hours, in the client's currency.
+	RoundedAmount decimal.Decimal
+}
+
+// The impact of a rounding policy on the time entries of a period, per client.
+type RoundingReport struct {
+	From  time.Time
+	To    time.Time
+	Lines []RoundingReportLine
+}
+
+var secondsPerHour = decimal.NewFromInt(3600)
+
+func (f RoundingPolicyFunc) RoundHours(hours decimal.Decimal) decimal.Decimal {
+	return f(hours)
+}
+
+// Rounds hours up to the next multiple of increment, e.g. 6 or 15 minutes.
+func RoundUp(increment time.Duration) RoundingPolicy {
+	return incrementRounding(increment, decimal.Decimal.Ceil)
+}
+
+// Rounds hours to the nearest multiple of increment, halves rounded up.
+func RoundNearest(increment time.Duration) RoundingPolicy {
+	return incrementRounding(increment, func(d decimal.Decimal) decimal.Decimal { return d.Round(0) })
+}
+
+// Rounds hours down to the previous multiple of increment.
+func RoundDown(increment time.Duration) RoundingPolicy {
+	return incrementRounding(increment, decimal.Decimal.Floor)
+}
+
+// Bills entries shorter than minimum as minimum, and rounds any other entry with policy.
+// A nil policy leaves other entries as they are. Entries without hours are left at zero.
+func MinimumIncrement(minimum time.Duration, policy RoundingPolicy) RoundingPolicy {
+	minimumHours := durationHours(minimum)
+
+	return RoundingPolicyFunc(func(hours decimal.Decimal) decimal.Decimal {
+		if !hours.IsPositive() {
+			return hours
+		}
+
+		if hours.LessThan(minimumHours) {
+			return minimumHours
+		}
+
+		if policy == nil {
+			return hours
+		}
+
+		return policy.RoundHours(hours)
+	})
+}
+
+// Rounds hours to multiples of increment, rounding the number of increments with round.
+// Results are rounded to 4 decimal places for increments that aren't a fraction of an
+// hour with a finite decimal expansion, e.g. 7 minutes.
+func incrementRounding(increment time.Duration, round func(decimal.Decimal) decimal.Decimal) RoundingPolicy {
+	seconds := decimal.NewFromInt(int64(increment / time.Second))
+
+	return RoundingPolicyFunc(func(hours decimal.Decimal) decimal.Decimal {
+		if !seconds.IsPositive() {
+			return hours
+		}
+
+		increments := round(hours.Mul(secondsPerHour).Div(seconds))
+		return increments.Mul(seconds).Div(secondsPerHour).Round(4)
+	})
+}
+
+func durationHours(d time.Duration) decimal.Decimal {
+	return decimal.NewFromInt(int64(d / time.Second)).Div(secondsPerHour).Round(4)
+}
+
+// Rounds the hours of time entries created via duration with policy, before they are sent.
+func WithHoursRounding(policy RoundingPolicy) ClientOption {
+	return func(client *internalClient) {
+		client.rounding = policy
+	}
+}
+
+// Rounds hours with the policy of WithHoursRounding, if any.
+func (client *internalClient) roundHours(hours *decimal.Decimal) *decimal.Decimal {
+	if hours == nil || client.rounding == nil {
+		return hours
+	}
+
+	return OptionalDecimal(client.rounding.RoundHours(*hours))
+}
+
+// Returns a copy of the request with its hours rounded by policy.
+func (r CreateTimeEntryViaDurationRequest) Rounded(policy RoundingPolicy) CreateTimeEntryViaDurationRequest {
+	if r.Hours != nil {
+		r.Hours = OptionalDecimal(policy.RoundHours(*r.Hours))
+	}
+
+	return r
+}
+
+// Compares the hours of the time entries spent within [from, to] with their hours rounded
+// entry by entry, and the billable amounts of both, per client.
+func BuildRoundingReport(api TimeEntriesApi, policy RoundingPolicy, from, to time.Time) (RoundingReport, error) {
+	report := RoundingReport{From: from, To: to}
+
+	entries, err := api.GetAllPages(GetTimeEntriesParams{FromDate: OptionalTime(from), ToDate: OptionalTime(to)})
+
+	if err != nil {
+		return report, err
+	}
+
+	lines := make(map[uint]*RoundingReportLine)
+
+	for _, e := range entries {
+		line, ok := lines[e.Client.Id]
+
+		if !ok {
+			line = &RoundingReportLine{Client: e.Client}
+			lines[e.Client.Id] = line
+		}
+
+		rounded := policy.RoundHours(e.Hours)
+		line.Entries++
+		line.RawHours = line.RawHours.Add(e.Hours)
+		line.RoundedHours = line.RoundedHours.Add(rounded)
+
+		if e.Billable && e.BillableRate != nil {
+			line.RawAmount = line.RawAmount.Add(e.Hours.Mul(*e.BillableRate))
+			line.RoundedAmount = line.RoundedAmount.Add(rounded.Mul(*e.BillableRate))
+		}
+	}
+
+	for _, line := range lines {
+		line.RawAmount = NewMoney(line.RawAmount, line.Client.Currency).Round().Amount
+		line.RoundedAmount = NewMoney(line.RoundedAmount, line.Client.Currency).Round().Amount
+		report.Lines = append(report.Lines, *line)
+	}
+
+	sort.Slice(report.Lines, func(i, j int) bool {
+		return report.Lines[i].Client.Name < report.Lines[j].Client.Name
+	})
+
+	return report, nil
+}
+
+// The hours added by rounding. Negative when rounding down.
+func (l RoundingReportLine) HoursDelta() decimal.Decimal {
+	return l.RoundedHours.Sub(l.RawHours)
+}
+
+// The billable amount added by rounding. Negative when rounding down.
+func (l RoundingReportLine) AmountDelta() decimal.Decimal {
+	return l.RoundedAmount.Sub(l.RawAmount)
+}
+
+// Writes one row per client with the raw and rounded hours and amounts.
+func (r RoundingReport) WriteCsv(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	header := []string{"client", "currency", "entries", "raw_hours", "rounded_hours", "hours_delta", "raw_amount", "rounded_amount", "amount_delta"}
+
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+
+	for _, line := range r.Lines {
+		currency := line.Client.Currency
+		row := []string{
+			line.Client.Name,
+			currency.String(),
+			strconv.Itoa(line.Entries),
+			line.RawHours.String(),
+			line.RoundedHours.String(),
+			line.HoursDelta().String(),
+			formatCurrencyAmount(line.RawAmount, currency),
+			formatCurrencyAmount(line.RoundedAmount, currency),
+			formatCurrencyAmount(line.AmountDelta(), currency),
+		}
+
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
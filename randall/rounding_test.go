@@ -0,0 +1,62 @@
+package randall
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestIncrementRounding(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy RoundingPolicy
+		hours  string
+		want   string
+	}{
+		{"up to 6 minutes", RoundUp(6 * time.Minute), "1.01", "1.1"},
+		{"up on a multiple", RoundUp(6 * time.Minute), "1.5", "1.5"},
+		{"up from zero", RoundUp(6 * time.Minute), "0", "0"},
+		{"up to 15 minutes", RoundUp(15 * time.Minute), "1.01", "1.25"},
+		{"up to 7 minutes", RoundUp(7 * time.Minute), "0.1", "0.1167"},
+		{"nearest below half", RoundNearest(15 * time.Minute), "1.12", "1"},
+		{"nearest at half", RoundNearest(15 * time.Minute), "1.125", "1.25"},
+		{"nearest above half", RoundNearest(15 * time.Minute), "1.2", "1.25"},
+		{"down to 15 minutes", RoundDown(15 * time.Minute), "1.24", "1"},
+		{"down on a multiple", RoundDown(15 * time.Minute), "1.25", "1.25"},
+		{"zero increment", RoundUp(0), "1.234", "1.234"},
+		{"sub-second increment", RoundUp(500 * time.Millisecond), "1.234", "1.234"},
+		{"minimum below", MinimumIncrement(15*time.Minute, RoundUp(6*time.Minute)), "0.1", "0.25"},
+		{"minimum above", MinimumIncrement(15*time.Minute, RoundUp(6*time.Minute)), "0.31", "0.4"},
+		{"minimum without hours", MinimumIncrement(15*time.Minute, nil), "0", "0"},
+		{"minimum without policy", MinimumIncrement(15*time.Minute, nil), "0.31", "0.31"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.policy.RoundHours(decimal.RequireFromString(tt.hours))
+
+			if !got.Equal(decimal.RequireFromString(tt.want)) {
+				t.Errorf("RoundHours(%s) = %s, want %s", tt.hours, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDurationHours(t *testing.T) {
+	tests := []struct {
+		duration time.Duration
+		want     string
+	}{
+		{0, "0"},
+		{20 * time.Minute, "0.3333"},
+		{90 * time.Minute, "1.5"},
+		{time.Hour + 1500*time.Millisecond, "1.0003"},
+	}
+
+	for _, tt := range tests {
+		if got := durationHours(tt.duration); !got.Equal(decimal.RequireFromString(tt.want)) {
+			t.Errorf("durationHours(%s) = %s, want %s", tt.duration, got, tt.want)
+		}
+	}
+}
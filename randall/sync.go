@@ -0,0 +1,288 @@
+package randall
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// The Harvest resources that can be synced incrementally.
+type SyncResource string
+
+const (
+	SyncClients         SyncResource = "clients"
+	SyncContacts        SyncResource = "contacts"
+	SyncProjects        SyncResource = "projects"
+	SyncTasks           SyncResource = "tasks"
+	SyncUserAssignments SyncResource = "user_assignments"
+	SyncTaskAssignments SyncResource = "task_assignments"
+	SyncUsers           SyncResource = "users"
+	SyncRoles           SyncResource = "roles"
+	SyncTimeEntries     SyncResource = "time_entries"
+	SyncExpenses        SyncResource = "expenses"
+	SyncEstimates       SyncResource = "estimates"
+	SyncInvoices        SyncResource = "invoices"
+)
+
+// The kind of change a SyncEvent reports.
+type SyncEventType string
+
+const (
+	SyncEventCreated SyncEventType = "created"
+	SyncEventUpdated SyncEventType = "updated"
+	SyncEventDeleted SyncEventType = "deleted"
+)
+
+// A change to a single Harvest record.
+type SyncEvent struct {
+	Type     SyncEventType
+	Resource SyncResource
+	Id       uint
+	// The updated_at timestamp of the record. Zero for deleted records.
+	UpdatedAt time.Time
+	// The JSON object as returned by Harvest. Empty for deleted records.
+	Data json.RawMessage
+}
+
+// Receives the events emitted by a SyncEngine. Returning an error stops the sync
+// without advancing the watermark of the resource being synced.
+type SyncHandler func(event SyncEvent) error
+
+// Persists the last seen updated_at watermark of every synced resource between runs.
+type WatermarkStore interface {
+	// Returns the watermark of the resource, or the zero time if it has never been synced.
+	GetWatermark(resource SyncResource) (time.Time, error)
+	SetWatermark(resource SyncResource, watermark time.Time) error
+}
+
+// A WatermarkStore that keeps watermarks in memory only.
+type MemoryWatermarkStore struct {
+	mu         sync.Mutex
+	watermarks map[SyncResource]time.Time
+}
+
+// A WatermarkStore that keeps watermarks in a JSON file.
+type FileWatermarkStore struct {
+	file jsonFileMap[SyncResource, time.Time]
+}
+
+// Fetches records changed in Harvest since the last run and emits them to a SyncHandler,
+// so downstream systems can mirror Harvest incrementally.
+type SyncEngine struct {
+	client  *HarvestClient
+	store   WatermarkStore
+	handler SyncHandler
+}
+
+// The fields every synced Harvest object has in common.
+type syncRecord struct {
+	Id        uint      `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Every resource, in an order where referenced records are synced before the records
+// referencing them.
+var allSyncResources = []SyncResource{
+	SyncClients,
+	SyncContacts,
+	SyncRoles,
+	SyncUsers,
+	SyncTasks,
+	SyncProjects,
+	SyncUserAssignments,
+	SyncTaskAssignments,
+	SyncEstimates,
+	SyncInvoices,
+	SyncTimeEntries,
+	SyncExpenses,
+}
+
+func NewMemoryWatermarkStore() *MemoryWatermarkStore {
+	return &MemoryWatermarkStore{
+		watermarks: make(map[SyncResource]time.Time),
+	}
+}
+
+func (s *MemoryWatermarkStore) GetWatermark(resource SyncResource) (time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.watermarks[resource], nil
+}
+
+func (s *MemoryWatermarkStore) SetWatermark(resource SyncResource, watermark time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.watermarks[resource] = watermark
+	return nil
+}
+
+// Initializes a FileWatermarkStore backed by the file at path. The file is created on the
+// first call to SetWatermark.
+func NewFileWatermarkStore(path string) *FileWatermarkStore {
+	return &FileWatermarkStore{
+		file: jsonFileMap[SyncResource, time.Time]{path: path},
+	}
+}
+
+func (s *FileWatermarkStore) GetWatermark(resource SyncResource) (time.Time, error) {
+	watermark, _, err := s.file.get(resource)
+	return watermark, err
+}
+
+func (s *FileWatermarkStore) SetWatermark(resource SyncResource, watermark time.Time) error {
+	return s.file.set(resource, watermark)
+}
+
+// Initializes a new SyncEngine. Watermarks are read from and written to store, and every
+// created or updated record is passed to handler.
+func NewSyncEngine(client *HarvestClient, store WatermarkStore, handler SyncHandler) *SyncEngine {
+	return &SyncEngine{
+		client:  client,
+		store:   store,
+		handler: handler,
+	}
+}
+
+// Syncs the given resources, or every resource if none are given. Records updated at or after
+// a resource's watermark are emitted oldest first, so the handler should be idempotent.
+func (e *SyncEngine) Sync(resources ...SyncResource) error {
+	if len(resources) == 0 {
+		resources = allSyncResources
+	}
+
+	for _, resource := range resources {
+		if err := e.syncResource(resource); err != nil {
+			return fmt.Errorf("syncing %s: %w", resource, err)
+		}
+	}
+
+	return nil
+}
+
+func (e *SyncEngine) syncResource(resource SyncResource) error {
+	watermark, err := e.store.GetWatermark(resource)
+
+	if err != nil {
+		return err
+	}
+
+	params := HarvestCollectionParams{
+		PerPage:      OptionalInt(2000),
+		UpdatedSince: watermark,
+	}
+
+	raws, err := fetchSyncResource[json.RawMessage](e.client, resource, params, 0)
+
+	if err != nil {
+		return err
+	}
+
+	events := make([]SyncEvent, 0, len(raws))
+
+	for _, raw := range raws {
+		var record syncRecord
+
+		if err := json.Unmarshal(raw, &record); err != nil {
+			return err
+		}
+
+		// Harvest does not filter every resource by updated_since, e.g. roles
+		if record.UpdatedAt.Before(watermark) {
+			continue
+		}
+
+		eventType := SyncEventUpdated
+
+		if watermark.IsZero() || !record.CreatedAt.Before(watermark) {
+			eventType = SyncEventCreated
+		}
+
+		events = append(events, SyncEvent{
+			Type:      eventType,
+			Resource:  resource,
+			Id:        record.Id,
+			UpdatedAt: record.UpdatedAt,
+			Data:      raw,
+		})
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].UpdatedAt.Before(events[j].UpdatedAt)
+	})
+
+	for _, event := range events {
+		if err := e.handler(event); err != nil {
+			return err
+		}
+
+		if event.UpdatedAt.After(watermark) {
+			watermark = event.UpdatedAt
+		}
+	}
+
+	if len(events) == 0 {
+		return nil
+	}
+
+	return e.store.SetWatermark(resource, watermark)
+}
+
+// Decodes the Harvest object carried by the event into v, e.g. a *TimeEntry for
+// events of the SyncTimeEntries resource.
+func (event SyncEvent) Decode(v interface{}) error {
+	if len(event.Data) == 0 {
+		return fmt.Errorf("%s event for %s %d carries no data", event.Type, event.Resource, event.Id)
+	}
+
+	return json.Unmarshal(event.Data, v)
+}
+
+// Retrieves every page of the resource matching params, waiting pageDelay between pages.
+func fetchSyncResource[T any](client *HarvestClient, resource SyncResource, params HarvestCollectionParams, pageDelay time.Duration) ([]T, error) {
+	var fetch func(params ...HarvestCollectionParams) (HarvestResponse, error)
+
+	switch resource {
+	case SyncClients:
+		fetch = client.Clients.GetAll
+	case SyncContacts:
+		fetch = client.Contacts.GetAll
+	case SyncProjects:
+		fetch = client.Projects.GetAll
+	case SyncTasks:
+		fetch = client.Tasks.GetAllTasks
+	case SyncUserAssignments:
+		fetch = client.Projects.GetAllUserAssigments
+	case SyncTaskAssignments:
+		fetch = client.Projects.GetAllTaskAssigments
+	case SyncUsers:
+		fetch = client.Users.AllUsers
+	case SyncRoles:
+		fetch = client.Roles.GetAllRoles
+	case SyncExpenses:
+		fetch = client.Expenses.GetAll
+	case SyncEstimates:
+		fetch = client.Estimates.GetAll
+	case SyncInvoices:
+		fetch = client.Invoices.GetAll
+	case SyncTimeEntries:
+		fetch = func(params ...HarvestCollectionParams) (HarvestResponse, error) {
+			return client.TimeEntries.GetAll(params[0].timeEntriesParams())
+		}
+	default:
+		return nil, fmt.Errorf("unsupported sync resource %q", resource)
+	}
+
+	return getAllPages[T](string(resource), func(page int) (HarvestResponse, error) {
+		if page > 1 && pageDelay > 0 {
+			time.Sleep(pageDelay)
+		}
+
+		params.Page = OptionalInt(page)
+		return fetch(params)
+	})
+}
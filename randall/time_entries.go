@@ -1,6 +1,7 @@
 package randall
 
 import (
+	"errors"
 	"fmt"
 	"net/url"
 	"time"
@@ -20,11 +21,12 @@ type GetTimeEntriesParams struct {
 	ClientId            int        `url:"client_id,omitempty"`
 	ProjectId           int        `url:"project_id,omitempty"`
 	TaskId              int        `url:"task_id,omitempty"`
-	ExternalReferenceId int        `url:"external_reference_id,omitempty"`
+	ExternalReferenceId string     `url:"external_reference_id,omitempty"`
 	IsBilled            *bool      `url:"is_billed,omitempty"`
 	IsRunning           *bool      `url:"is_running,omitempty"`
 	UpdateSince         *time.Time `url:"updated_since,omitempty"`
-	FromDate            *time.Time `url:"from,omitempty"`
+	FromDate            *time.Time `url:"from,omitempty" layout:"2006-01-02"`
+	ToDate              *time.Time `url:"to,omitempty" layout:"2006-01-02"`
 	StartDate           *time.Time `url:"start,omitempty"`
 	Page                *int       `url:"page,omitempty"`
 	PerPage             *int       `url:"per_page,omitempty"`
@@ -62,7 +64,7 @@ type CreateTimeEntryViaStartEndRequest struct {
 	SpentDate   time.Time          `json:"spent_date" layout:"2006-01-02"`
 	UserId      *uint              `json:"user_id,omitempty"`
 	StartedTime *string            `json:"started_time,omitempty"`
-	EndTime     *string            `json:"end_time,omitempty"`
+	EndTime     *string            `json:"ended_time,omitempty"`
 	Notes       *string            `json:"notes,omitempty"`
 	ExternalRef *ExternalReference `json:"external_reference,omitempty"`
 }
@@ -72,19 +74,61 @@ type UpdateTimeEntryRequest struct {
 	TaskId      *uint              `json:"task_id,omitempty"`
 	SpentDate   time.Time          `json:"spent_date,omitempty" layout:"2006-01-02"`
 	StartedTime *string            `json:"started_time,omitempty"`
-	EndTime     *string            `json:"end_time,omitempty"`
+	EndTime     *string            `json:"ended_time,omitempty"`
 	Hours       *decimal.Decimal   `json:"hours,omitempty"`
 	Notes       *string            `json:"notes,omitempty"`
 	ExternalRef *ExternalReference `json:"external_reference,omitempty"`
 }
 
+// A reference to the record of another service a time entry was tracked for. Harvest sends
+// the ids as strings, typically keys like "JIRA-123".
 type ExternalReference struct {
-	Id        uint   `json:"id"`
-	GroupId   uint   `json:"group_id"`
-	AccountId uint   `json:"account_id"`
+	Id        string `json:"id"`
+	GroupId   string `json:"group_id"`
+	AccountId string `json:"account_id"`
 	Permalink string `json:"permalink"`
 }
 
+// A time entry as returned by the Harvest API.
+type TimeEntry struct {
+	Id                uint               `json:"id"`
+	SpentDate         HarvestDate        `json:"spent_date"`
+	User              ObjectRef          `json:"user"`
+	Client            ClientRef          `json:"client"`
+	Project           ProjectRef         `json:"project"`
+	Task              ObjectRef          `json:"task"`
+	ExternalReference *ExternalReference `json:"external_reference"`
+	Invoice           *InvoiceRef        `json:"invoice"`
+	Hours             decimal.Decimal    `json:"hours"`
+	HoursWithoutTimer decimal.Decimal    `json:"hours_without_timer"`
+	RoundedHours      decimal.Decimal    `json:"rounded_hours"`
+	Notes             string             `json:"notes"`
+	IsLocked          bool               `json:"is_locked"`
+	LockedReason      string             `json:"locked_reason"`
+	IsClosed          bool               `json:"is_closed"`
+	IsBilled          bool               `json:"is_billed"`
+	TimerStartedAt    *time.Time         `json:"timer_started_at"`
+	StartedTime       string             `json:"started_time"`
+	EndedTime         string             `json:"ended_time"`
+	IsRunning         bool               `json:"is_running"`
+	Billable          bool               `json:"billable"`
+	Budgeted          bool               `json:"budgeted"`
+	BillableRate      *decimal.Decimal   `json:"billable_rate"`
+	CostRate          *decimal.Decimal   `json:"cost_rate"`
+	CreatedAt         time.Time          `json:"created_at"`
+	UpdatedAt         time.Time          `json:"updated_at"`
+}
+
+// The billable amount of the entry, its hours multiplied by its billable rate. Zero for
+// entries that are not billable.
+func (e TimeEntry) billableAmount() decimal.Decimal {
+	if !e.Billable || e.BillableRate == nil {
+		return decimal.Zero
+	}
+
+	return e.Hours.Mul(*e.BillableRate)
+}
+
 func newTimeEntriesV2(client *internalClient) TimeEntriesApi {
 	return TimeEntriesApi{
 		baseUrl: "v2/time_entries",
@@ -103,11 +147,27 @@ func (api TimeEntriesApi) GetAll(params ...GetTimeEntriesParams) (HarvestRespons
 	return api.client.doGet(api.baseUrl, param)
 }
 
+// Retrieves every page of time entries matching params as typed TimeEntry objects.
+func (api TimeEntriesApi) GetAllPages(params ...GetTimeEntriesParams) ([]TimeEntry, error) {
+	var param GetTimeEntriesParams
+
+	if len(params) > 0 {
+		param = params[0]
+	}
+
+	return getAllPages[TimeEntry]("time_entries", func(page int) (HarvestResponse, error) {
+		param.Page = OptionalInt(page)
+		return api.GetAll(param)
+	})
+}
+
 func (api TimeEntriesApi) GetTimeEntry(timeEntryId uint) (HarvestResponse, error) {
 	return api.client.doGet(fmt.Sprintf("%s/%d", api.baseUrl, timeEntryId))
 }
 
+// Creates a time entry with its hours rounded by the policy of WithHoursRounding, if any.
 func (api TimeEntriesApi) CreateViaDuration(req CreateTimeEntryViaDurationRequest) (HarvestResponse, error) {
+	req.Hours = api.client.roundHours(req.Hours)
 	return api.client.doPost(api.baseUrl, req)
 }
 
@@ -134,3 +194,90 @@ func (api TimeEntriesApi) RestartTimeEntry(timeEntryId uint) (HarvestResponse, e
 func (api TimeEntriesApi) StopTimeEntry(timeEntryId uint) (HarvestResponse, error) {
 	return api.client.doPatch(fmt.Sprintf("%s/%d/stop", api.baseUrl, timeEntryId))
 }
+
+// Creates a time entry via duration unless a time entry with the same external reference
+// already exists, in which case that entry is updated with the values of req instead.
+// Harvest responds with 201 Created in the first case and 200 OK in the second.
+func (api TimeEntriesApi) UpsertViaDuration(req CreateTimeEntryViaDurationRequest) (HarvestResponse, error) {
+	existing, err := api.findByExternalReference(req.ExternalRef, req.UserId)
+
+	if err != nil {
+		return HarvestResponse{}, err
+	}
+
+	if existing == nil {
+		return api.CreateViaDuration(req)
+	}
+
+	return api.UpdateTimeEntry(existing.Id, UpdateTimeEntryRequest{
+		ProjectId:   OptionalUInt(req.ProjectId),
+		TaskId:      OptionalUInt(req.TaskId),
+		SpentDate:   req.SpentDate,
+		Hours:       api.client.roundHours(req.Hours),
+		Notes:       req.Notes,
+		ExternalRef: req.ExternalRef,
+	})
+}
+
+// Creates a time entry via start and end time unless a time entry with the same external
+// reference already exists, in which case that entry is updated with the values of req instead.
+// Harvest responds with 201 Created in the first case and 200 OK in the second.
+func (api TimeEntriesApi) UpsertViaStartEnd(req CreateTimeEntryViaStartEndRequest) (HarvestResponse, error) {
+	existing, err := api.findByExternalReference(req.ExternalRef, req.UserId)
+
+	if err != nil {
+		return HarvestResponse{}, err
+	}
+
+	if existing == nil {
+		return api.CreateViaStartEnd(req)
+	}
+
+	return api.UpdateTimeEntry(existing.Id, UpdateTimeEntryRequest{
+		ProjectId:   OptionalUInt(req.ProjectId),
+		TaskId:      OptionalUInt(req.TaskId),
+		SpentDate:   req.SpentDate,
+		StartedTime: req.StartedTime,
+		EndTime:     req.EndTime,
+		Notes:       req.Notes,
+		ExternalRef: req.ExternalRef,
+	})
+}
+
+// Looks up the time entry whose external reference has the same id, group id and permalink
+// as ref. When userId is set only that user's time entries are considered.
+func (api TimeEntriesApi) findByExternalReference(ref *ExternalReference, userId *uint) (*TimeEntry, error) {
+	if ref == nil || ref.Id == "" {
+		return nil, errors.New("an external reference with an id is required to upsert a time entry")
+	}
+
+	params := GetTimeEntriesParams{
+		ExternalReferenceId: ref.Id,
+	}
+
+	if userId != nil {
+		params.UserId = int(*userId)
+	}
+
+	entries, err := api.GetAllPages(params)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range entries {
+		other := entries[i].ExternalReference
+
+		if other == nil || other.Id != ref.Id || other.GroupId != ref.GroupId {
+			continue
+		}
+
+		if ref.Permalink != "" && other.Permalink != ref.Permalink {
+			continue
+		}
+
+		return &entries[i], nil
+	}
+
+	return nil, nil
+}
@@ -0,0 +1,403 @@
+package randall
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// A policy time entries are checked against by a TimeEntryLinter.
+type TimeEntryRule interface {
+	// Identifies the rule in findings, e.g. "billable-without-notes".
+	Name() string
+	Check(input LintInput) []LintFinding
+}
+
+// The data rules check.
+type LintInput struct {
+	Entries []TimeEntry
+	// Every project of the account, active or archived, by id.
+	Projects map[uint]Project
+	// When the entries were fetched.
+	AsOf time.Time
+}
+
+// A violation of a rule by one or more time entries.
+type LintFinding struct {
+	Rule     string     `json:"rule"`
+	EntryIds []uint     `json:"entry_ids"`
+	User     ObjectRef  `json:"user"`
+	Project  ProjectRef `json:"project"`
+	Date     time.Time  `json:"date"`
+	Message  string     `json:"message"`
+}
+
+// The findings of a linter run over the time entries of a period.
+type LintReport struct {
+	From     time.Time     `json:"from"`
+	To       time.Time     `json:"to"`
+	Findings []LintFinding `json:"findings"`
+}
+
+// Checks time entries against configurable policy rules.
+type TimeEntryLinter struct {
+	client *HarvestClient
+	// The rules entries are checked against. Defaults to DefaultTimeEntryRules.
+	Rules []TimeEntryRule
+}
+
+type timeEntryRule struct {
+	name  string
+	check func(input LintInput) []LintFinding
+}
+
+// Initializes a new TimeEntryRule named name checking entries with check.
+func NewTimeEntryRule(name string, check func(input LintInput) []LintFinding) TimeEntryRule {
+	return timeEntryRule{name: name, check: check}
+}
+
+func (r timeEntryRule) Name() string {
+	return r.name
+}
+
+// Checks the input and sets the rule of every finding to the rule's name.
+func (r timeEntryRule) Check(input LintInput) []LintFinding {
+	findings := r.check(input)
+
+	for i := range findings {
+		findings[i].Rule = r.name
+	}
+
+	return findings
+}
+
+// Returns every built-in rule, flagging users who logged more than maxHoursPerDay hours on
+// a day.
+func DefaultTimeEntryRules(maxHoursPerDay decimal.Decimal) []TimeEntryRule {
+	return []TimeEntryRule{
+		MaxHoursPerDayRule(maxHoursPerDay),
+		OverlappingEntriesRule(),
+		BillableWithoutNotesRule(),
+		ArchivedProjectRule(),
+		RunningOvernightRule(),
+		WeekendEntriesRule(),
+		AfterProjectEndRule(),
+	}
+}
+
+// Flags users who logged more than maxHours hours on a day.
+func MaxHoursPerDayRule(maxHours decimal.Decimal) TimeEntryRule {
+	return NewTimeEntryRule("max-hours-per-day", func(input LintInput) []LintFinding {
+		var findings []LintFinding
+
+		for _, day := range entriesByUserDay(input.Entries) {
+			total := decimal.Zero
+
+			for _, e := range day {
+				total = total.Add(e.Hours)
+			}
+
+			if total.GreaterThan(maxHours) {
+				finding := newLintFinding(day...)
+				finding.Message = fmt.Sprintf("%s hours logged, more than %s", total, maxHours)
+				findings = append(findings, finding)
+			}
+		}
+
+		return findings
+	})
+}
+
+// Flags entries tracked with start and end times that overlap another entry of the same
+// user on the same day.
+func OverlappingEntriesRule() TimeEntryRule {
+	return NewTimeEntryRule("overlapping-entries", func(input LintInput) []LintFinding {
+		type period struct {
+			entry      TimeEntry
+			start, end time.Time
+		}
+
+		var findings []LintFinding
+
+		for _, day := range entriesByUserDay(input.Entries) {
+			var periods []period
+
+			for _, e := range day {
+				if e.StartedTime == "" || e.EndedTime == "" {
+					continue
+				}
+
+				start, startErr := parseClockTime(e.StartedTime)
+				end, endErr := parseClockTime(e.EndedTime)
+
+				if startErr != nil || endErr != nil {
+					continue
+				}
+
+				// An end time before the start time is on the following day
+				if end.Before(start) {
+					end = end.AddDate(0, 0, 1)
+				}
+
+				periods = append(periods, period{entry: e, start: start, end: end})
+			}
+
+			sort.Slice(periods, func(i, j int) bool {
+				return periods[i].start.Before(periods[j].start)
+			})
+
+			if len(periods) == 0 {
+				continue
+			}
+
+			// The period ending last so far, which later periods starting before its end overlap
+			latest := periods[0]
+
+			for _, current := range periods[1:] {
+				if current.start.Before(latest.end) {
+					finding := newLintFinding(latest.entry, current.entry)
+					finding.Message = fmt.Sprintf("%s-%s overlaps %s-%s",
+						current.entry.StartedTime, current.entry.EndedTime, latest.entry.StartedTime, latest.entry.EndedTime)
+					findings = append(findings, finding)
+				}
+
+				if current.end.After(latest.end) {
+					latest = current
+				}
+			}
+		}
+
+		return findings
+	})
+}
+
+// Flags billable entries without notes.
+func BillableWithoutNotesRule() TimeEntryRule {
+	return entryRule("billable-without-notes", func(e TimeEntry, _ LintInput) string {
+		if e.Billable && strings.TrimSpace(e.Notes) == "" {
+			return "billable entry has no notes"
+		}
+
+		return ""
+	})
+}
+
+// Flags entries on archived projects.
+func ArchivedProjectRule() TimeEntryRule {
+	return entryRule("archived-project", func(e TimeEntry, input LintInput) string {
+		if p, ok := input.Projects[e.Project.Id]; ok && !p.IsActive {
+			return fmt.Sprintf("project %s is archived", e.Project.Name)
+		}
+
+		return ""
+	})
+}
+
+// Flags timers still running that were started on a day before the day of AsOf.
+func RunningOvernightRule() TimeEntryRule {
+	return entryRule("running-overnight", func(e TimeEntry, input LintInput) string {
+		today := time.Date(input.AsOf.Year(), input.AsOf.Month(), input.AsOf.Day(), 0, 0, 0, 0, time.UTC)
+
+		if e.IsRunning && e.SpentDate.Before(today) {
+			return fmt.Sprintf("timer running since %s", e.SpentDate.Format("2006-01-02"))
+		}
+
+		return ""
+	})
+}
+
+// Flags entries logged on a Saturday or Sunday.
+func WeekendEntriesRule() TimeEntryRule {
+	return entryRule("weekend-entry", func(e TimeEntry, _ LintInput) string {
+		if d := e.SpentDate.Weekday(); d == time.Saturday || d == time.Sunday {
+			return fmt.Sprintf("entry logged on a %s", d)
+		}
+
+		return ""
+	})
+}
+
+// Flags entries logged after the end date of their project.
+func AfterProjectEndRule() TimeEntryRule {
+	return entryRule("after-project-end", func(e TimeEntry, input LintInput) string {
+		p, ok := input.Projects[e.Project.Id]
+
+		if ok && !p.EndsOn.IsZero() && e.SpentDate.After(p.EndsOn.Time) {
+			return fmt.Sprintf("project %s ended on %s", e.Project.Name, p.EndsOn.Format("2006-01-02"))
+		}
+
+		return ""
+	})
+}
+
+// Initializes a rule checking entries one at a time. check returns the message of the
+// finding, or an empty string if the entry complies.
+func entryRule(name string, check func(e TimeEntry, input LintInput) string) TimeEntryRule {
+	return NewTimeEntryRule(name, func(input LintInput) []LintFinding {
+		var findings []LintFinding
+
+		for _, e := range input.Entries {
+			if message := check(e, input); message != "" {
+				finding := newLintFinding(e)
+				finding.Message = message
+				findings = append(findings, finding)
+			}
+		}
+
+		return findings
+	})
+}
+
+// Groups entries by user and spent date.
+func entriesByUserDay(entries []TimeEntry) map[string][]TimeEntry {
+	days := make(map[string][]TimeEntry)
+
+	for _, e := range entries {
+		key := timesheetKey(e.User.Id, e.SpentDate.Time)
+		days[key] = append(days[key], e)
+	}
+
+	return days
+}
+
+// Initializes a finding for entries, taking its user, project and date from the first.
+// The project is left empty if the entries are on different projects.
+func newLintFinding(entries ...TimeEntry) LintFinding {
+	finding := LintFinding{
+		User:    entries[0].User,
+		Project: entries[0].Project,
+		Date:    entries[0].SpentDate.Time,
+	}
+
+	for _, e := range entries {
+		finding.EntryIds = append(finding.EntryIds, e.Id)
+
+		if e.Project.Id != finding.Project.Id {
+			finding.Project = ProjectRef{}
+		}
+	}
+
+	return finding
+}
+
+// Initializes a new TimeEntryLinter checking entries against rules, or
+// DefaultTimeEntryRules flagging more than 24 hours a day if none are passed.
+func NewTimeEntryLinter(client *HarvestClient, rules ...TimeEntryRule) *TimeEntryLinter {
+	if len(rules) == 0 {
+		rules = DefaultTimeEntryRules(decimal.NewFromInt(24))
+	}
+
+	return &TimeEntryLinter{
+		client: client,
+		Rules:  rules,
+	}
+}
+
+// Checks the time entries spent within [from, to] against every rule. Findings are sorted
+// by date, user, rule and entry.
+func (l *TimeEntryLinter) Lint(from, to time.Time) (LintReport, error) {
+	report := LintReport{From: from, To: to}
+
+	entries, err := l.client.TimeEntries.GetAllPages(GetTimeEntriesParams{FromDate: OptionalTime(from), ToDate: OptionalTime(to)})
+
+	if err != nil {
+		return report, err
+	}
+
+	projects, err := l.client.Projects.GetAllPages()
+
+	if err != nil {
+		return report, err
+	}
+
+	report.Findings = LintTimeEntries(entries, projects, time.Now(), l.Rules...)
+	return report, nil
+}
+
+// Checks entries against rules without fetching anything. projects should contain the
+// projects of the entries for the rules that need them.
+func LintTimeEntries(entries []TimeEntry, projects []Project, asOf time.Time, rules ...TimeEntryRule) []LintFinding {
+	input := LintInput{
+		Entries:  entries,
+		Projects: make(map[uint]Project, len(projects)),
+		AsOf:     asOf,
+	}
+
+	for _, p := range projects {
+		input.Projects[p.Id] = p
+	}
+
+	var findings []LintFinding
+
+	for _, rule := range rules {
+		findings = append(findings, rule.Check(input)...)
+	}
+
+	sort.SliceStable(findings, func(i, j int) bool {
+		a, b := findings[i], findings[j]
+
+		if !a.Date.Equal(b.Date) {
+			return a.Date.Before(b.Date)
+		}
+
+		if a.User.Name != b.User.Name {
+			return a.User.Name < b.User.Name
+		}
+
+		if a.Rule != b.Rule || len(a.EntryIds) == 0 || len(b.EntryIds) == 0 {
+			return a.Rule < b.Rule
+		}
+
+		return a.EntryIds[0] < b.EntryIds[0]
+	})
+
+	return findings
+}
+
+// Writes one row per finding.
+func (r LintReport) WriteCsv(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	header := []string{"date", "rule", "user", "project", "entry_ids", "message"}
+
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+
+	for _, f := range r.Findings {
+		ids := make([]string, len(f.EntryIds))
+
+		for i, id := range f.EntryIds {
+			ids[i] = strconv.FormatUint(uint64(id), 10)
+		}
+
+		row := []string{
+			f.Date.Format("2006-01-02"),
+			f.Rule,
+			f.User.Name,
+			f.Project.Name,
+			strings.Join(ids, " "),
+			f.Message,
+		}
+
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// Writes the report as indented JSON.
+func (r LintReport) WriteJson(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
@@ -0,0 +1,175 @@
+package randall
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// The part of a time entry within one day of the user's timezone.
+type TimeEntryPeriod struct {
+	// The day of the period in the user's timezone, at midnight UTC like other spent dates.
+	SpentDate time.Time
+	Start     time.Time
+	End       time.Time
+}
+
+// Harvest can't express an end time of midnight, so periods ending at midnight end at this
+// time of day.
+const lastClockTime = "11:59pm"
+
+// Splits [start, end) at every midnight of loc, returning one period per day it touches
+// with its start and end in loc.
+func SplitAtMidnight(start, end time.Time, loc *time.Location) []TimeEntryPeriod {
+	start, end = start.In(loc), end.In(loc)
+	var periods []TimeEntryPeriod
+
+	for start.Before(end) {
+		y, m, d := start.Date()
+		partEnd := end
+
+		if midnight := time.Date(y, m, d+1, 0, 0, 0, 0, loc); midnight.Before(end) {
+			partEnd = midnight
+		}
+
+		periods = append(periods, TimeEntryPeriod{
+			SpentDate: time.Date(y, m, d, 0, 0, 0, 0, time.UTC),
+			Start:     start,
+			End:       partEnd,
+		})
+
+		start = partEnd
+	}
+
+	return periods
+}
+
+// The duration of the period in hours.
+func (p TimeEntryPeriod) Hours() decimal.Decimal {
+	return durationHours(p.End.Sub(p.Start))
+}
+
+// Returns the start and end of the period as Harvest clock times, e.g. "8:00am" and
+// "9:30am". Periods ending at midnight end at 11:59pm.
+func (p TimeEntryPeriod) ClockTimes() (string, string) {
+	end := formatClockTime(p.End)
+
+	if y, m, d := p.Start.Date(); !p.End.Before(time.Date(y, m, d+1, 0, 0, 0, 0, p.Start.Location())) {
+		end = lastClockTime
+	}
+
+	return formatClockTime(p.Start), end
+}
+
+// Formats t as a Harvest clock time in the timezone, e.g. "8:00am".
+func FormatClockTime(t time.Time, tz Timezone) (string, error) {
+	loc, err := tz.Location()
+
+	if err != nil {
+		return "", err
+	}
+
+	return formatClockTime(t.In(loc)), nil
+}
+
+// Initializes requests creating time entries for [start, end) with their start and end
+// times in the user's Harvest timezone. Periods crossing midnight in that timezone are
+// split into one request per day.
+func NewTimeEntriesViaStartEnd(projectId, taskId uint, start, end time.Time, tz Timezone) ([]CreateTimeEntryViaStartEndRequest, error) {
+	if !end.After(start) {
+		return nil, errors.New("the end of a time entry must be after its start")
+	}
+
+	loc, err := tz.Location()
+
+	if err != nil {
+		return nil, err
+	}
+
+	periods := SplitAtMidnight(start, end, loc)
+	requests := make([]CreateTimeEntryViaStartEndRequest, len(periods))
+
+	for i, p := range periods {
+		started, ended := p.ClockTimes()
+
+		requests[i] = CreateTimeEntryViaStartEndRequest{
+			ProjectId:   projectId,
+			TaskId:      taskId,
+			SpentDate:   p.SpentDate,
+			StartedTime: OptionalString(started),
+			EndTime:     OptionalString(ended),
+		}
+	}
+
+	return requests, nil
+}
+
+// Sets the spent date and the start and end times of the request from [start, end) in the
+// user's Harvest timezone. Fails if the period crosses midnight in that timezone, see
+// NewTimeEntriesViaStartEnd.
+func (r *CreateTimeEntryViaStartEndRequest) SetPeriod(start, end time.Time, tz Timezone) error {
+	requests, err := NewTimeEntriesViaStartEnd(r.ProjectId, r.TaskId, start, end, tz)
+
+	if err != nil {
+		return err
+	}
+
+	if len(requests) > 1 {
+		return fmt.Errorf("the time entry crosses midnight in %s", tz)
+	}
+
+	r.SpentDate = requests[0].SpentDate
+	r.StartedTime = requests[0].StartedTime
+	r.EndTime = requests[0].EndTime
+	return nil
+}
+
+// Returns the start and end of a time entry tracked with start and end times, in the
+// user's Harvest timezone. A running entry ends now.
+func (e TimeEntry) Period(tz Timezone) (start, end time.Time, err error) {
+	if e.StartedTime == "" {
+		return start, end, fmt.Errorf("time entry %d has no start time", e.Id)
+	}
+
+	loc, err := tz.Location()
+
+	if err != nil {
+		return start, end, err
+	}
+
+	y, m, d := e.SpentDate.Date()
+	atSpentDate := func(clock string) (time.Time, error) {
+		t, err := parseClockTime(clock)
+
+		if err != nil {
+			return t, err
+		}
+
+		return time.Date(y, m, d, t.Hour(), t.Minute(), 0, 0, loc), nil
+	}
+
+	if start, err = atSpentDate(e.StartedTime); err != nil {
+		return start, end, err
+	}
+
+	if e.EndedTime == "" {
+		if !e.IsRunning {
+			return start, end, fmt.Errorf("time entry %d has no end time", e.Id)
+		}
+
+		return start, time.Now().In(loc), nil
+	}
+
+	if end, err = atSpentDate(e.EndedTime); err != nil {
+		return start, end, err
+	}
+
+	// An end time before the start time is on the following day
+	if end.Before(start) {
+		end = end.AddDate(0, 0, 1)
+	}
+
+	return start, end, nil
+}
@@ -0,0 +1,364 @@
+package randall
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Days nobody is expected to log time on.
+type HolidayCalendar interface {
+	IsHoliday(day time.Time) bool
+}
+
+// A HolidayCalendar of fixed dates.
+type Holidays struct {
+	// The names of the holidays by date (YYYY-MM-DD).
+	days map[string]string
+}
+
+// A working day on which a user logged less time than expected.
+type TimesheetGap struct {
+	User          ObjectRef
+	Date          time.Time
+	ExpectedHours decimal.Decimal
+	LoggedHours   decimal.Decimal
+}
+
+// Notifies users of their timesheet gaps.
+type TimesheetNotifier interface {
+	// Notifies the user of their gaps, sorted by date.
+	Notify(user User, gaps []TimesheetGap) error
+}
+
+// A TimesheetNotifier implemented by a function.
+type TimesheetNotifierFunc func(user User, gaps []TimesheetGap) error
+
+// The gaps of a user found by a timesheet check and whether they were notified of them.
+type TimesheetReminder struct {
+	User User
+	Gaps []TimesheetGap
+	// Whether the notifier was called. Always false for dry runs.
+	Sent bool
+	Err  error
+}
+
+// Finds working days on which active users logged less time than their weekly capacity
+// requires and notifies them.
+type TimesheetChecker struct {
+	client   *HarvestClient
+	notifier TimesheetNotifier
+	// The days nobody is expected to log time on. Optional.
+	Holidays HolidayCalendar
+	// The days of the week users are expected to log time on. Defaults to Monday to Friday.
+	WorkingDays []time.Weekday
+	// Days missing at most this many hours aren't reported as gaps.
+	Tolerance decimal.Decimal
+	// Finds gaps without notifying users.
+	DryRun bool
+}
+
+var defaultWorkingDays = []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday}
+
+func NewHolidays() *Holidays {
+	return &Holidays{
+		days: make(map[string]string),
+	}
+}
+
+// Adds a holiday on the date of day.
+func (h *Holidays) Add(day time.Time, name string) {
+	h.days[day.Format("2006-01-02")] = name
+}
+
+func (h *Holidays) IsHoliday(day time.Time) bool {
+	_, ok := h.days[day.Format("2006-01-02")]
+	return ok
+}
+
+// Returns the name of the holiday on the date of day, if any.
+func (h *Holidays) Name(day time.Time) (string, bool) {
+	name, ok := h.days[day.Format("2006-01-02")]
+	return name, ok
+}
+
+// Reads holidays from CSV with a header row, a date column (YYYY-MM-DD) and an optional
+// name column.
+func ReadCsvHolidays(r io.Reader) (*Holidays, error) {
+	cr := csv.NewReader(r)
+	cr.TrimLeadingSpace = true
+
+	header, err := cr.Read()
+
+	if err != nil {
+		return nil, fmt.Errorf("reading holiday header: %w", err)
+	}
+
+	columns := make(map[string]int, len(header))
+
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+
+	if _, ok := columns["date"]; !ok {
+		return nil, errors.New("holidays are missing the date column")
+	}
+
+	holidays := NewHolidays()
+
+	for line := 2; ; line++ {
+		record, err := cr.Read()
+
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		date, err := time.Parse("2006-01-02", strings.TrimSpace(record[columns["date"]]))
+
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid date %q", line, record[columns["date"]])
+		}
+
+		var name string
+
+		if i, ok := columns["name"]; ok {
+			name = strings.TrimSpace(record[i])
+		}
+
+		holidays.Add(date, name)
+	}
+
+	return holidays, nil
+}
+
+// Reads holidays from the CSV file at path, see ReadCsvHolidays.
+func ReadCsvHolidaysFile(path string) (*Holidays, error) {
+	f, err := os.Open(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer f.Close()
+	return ReadCsvHolidays(f)
+}
+
+// The hours missing from the day.
+func (g TimesheetGap) MissingHours() decimal.Decimal {
+	return g.ExpectedHours.Sub(g.LoggedHours)
+}
+
+func (f TimesheetNotifierFunc) Notify(user User, gaps []TimesheetGap) error {
+	return f(user, gaps)
+}
+
+// Initializes a new TimesheetChecker notifying users of their gaps with notifier.
+func NewTimesheetChecker(client *HarvestClient, notifier TimesheetNotifier) *TimesheetChecker {
+	return &TimesheetChecker{
+		client:   client,
+		notifier: notifier,
+	}
+}
+
+// Returns the gaps of every active user on the working days within [from, to], sorted by
+// user and date. A user is expected to log their weekly capacity spread evenly over the
+// working days, and nothing on days before they were created or if they have no capacity.
+func (c *TimesheetChecker) Check(from, to time.Time) ([]TimesheetGap, error) {
+	users, err := c.client.Users.AllUsersPages(HarvestCollectionParams{IsActive: OptionalBool(true)})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return c.check(users, from, to)
+}
+
+// Checks the timesheets of [from, to] and notifies every user with gaps of them, unless
+// DryRun is set. Failures to notify a user are reported in their reminder's Err and don't
+// stop the run.
+func (c *TimesheetChecker) Run(from, to time.Time) ([]TimesheetReminder, error) {
+	if c.notifier == nil && !c.DryRun {
+		return nil, errors.New("no timesheet notifier is configured")
+	}
+
+	users, err := c.client.Users.AllUsersPages(HarvestCollectionParams{IsActive: OptionalBool(true)})
+
+	if err != nil {
+		return nil, err
+	}
+
+	gaps, err := c.check(users, from, to)
+
+	if err != nil {
+		return nil, err
+	}
+
+	byUser := make(map[uint][]TimesheetGap)
+
+	for _, gap := range gaps {
+		byUser[gap.User.Id] = append(byUser[gap.User.Id], gap)
+	}
+
+	var reminders []TimesheetReminder
+
+	for _, user := range users {
+		userGaps, ok := byUser[user.Id]
+
+		if !ok {
+			continue
+		}
+
+		reminder := TimesheetReminder{User: user, Gaps: userGaps}
+
+		if !c.DryRun {
+			reminder.Err = c.notifier.Notify(user, userGaps)
+			reminder.Sent = reminder.Err == nil
+		}
+
+		reminders = append(reminders, reminder)
+	}
+
+	return reminders, nil
+}
+
+func (c *TimesheetChecker) check(users []User, from, to time.Time) ([]TimesheetGap, error) {
+	days := workingDays(from, to, c.WorkingDays, c.Holidays)
+
+	if len(days) == 0 {
+		return nil, nil
+	}
+
+	entries, err := c.client.TimeEntries.GetAllPages(GetTimeEntriesParams{FromDate: OptionalTime(from), ToDate: OptionalTime(to)})
+
+	if err != nil {
+		return nil, err
+	}
+
+	logged := make(map[string]decimal.Decimal)
+
+	for _, e := range entries {
+		key := timesheetKey(e.User.Id, e.SpentDate.Time)
+		logged[key] = logged[key].Add(e.Hours)
+	}
+
+	workingDaysPerWeek := len(workingWeekdays(c.WorkingDays))
+	var gaps []TimesheetGap
+
+	for _, user := range users {
+		if user.WeeklyCapacity == 0 {
+			continue
+		}
+
+		expected := decimal.NewFromInt(int64(user.WeeklyCapacity)).
+			Div(secondsPerHour).Div(decimal.NewFromInt(int64(workingDaysPerWeek))).Round(2)
+		created := time.Date(user.CreatedAt.Year(), user.CreatedAt.Month(), user.CreatedAt.Day(), 0, 0, 0, 0, time.UTC)
+
+		for _, day := range days {
+			if day.Before(created) {
+				continue
+			}
+
+			hours := logged[timesheetKey(user.Id, day)]
+
+			if expected.Sub(hours).GreaterThan(c.Tolerance) {
+				gaps = append(gaps, TimesheetGap{
+					User:          ObjectRef{Id: user.Id, Name: user.FirstName + " " + user.LastName},
+					Date:          day,
+					ExpectedHours: expected,
+					LoggedHours:   hours,
+				})
+			}
+		}
+	}
+
+	sort.SliceStable(gaps, func(i, j int) bool {
+		return gaps[i].User.Name < gaps[j].User.Name
+	})
+
+	return gaps, nil
+}
+
+// Returns the days within [from, to] on one of weekdays that aren't holidays, at midnight
+// UTC. weekdays defaults to Monday to Friday if empty, holidays is optional.
+func workingDays(from, to time.Time, weekdays []time.Weekday, holidays HolidayCalendar) []time.Time {
+	weekdays = workingWeekdays(weekdays)
+
+	var days []time.Time
+	day := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC)
+	last := time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, time.UTC)
+
+	for ; !day.After(last); day = day.AddDate(0, 0, 1) {
+		if !containsWeekday(weekdays, day.Weekday()) {
+			continue
+		}
+
+		if holidays != nil && holidays.IsHoliday(day) {
+			continue
+		}
+
+		days = append(days, day)
+	}
+
+	return days
+}
+
+// Returns weekdays, or Monday to Friday if it is empty.
+func workingWeekdays(weekdays []time.Weekday) []time.Weekday {
+	if len(weekdays) == 0 {
+		return defaultWorkingDays
+	}
+
+	return weekdays
+}
+
+func containsWeekday(weekdays []time.Weekday, d time.Weekday) bool {
+	for _, w := range weekdays {
+		if w == d {
+			return true
+		}
+	}
+
+	return false
+}
+
+func timesheetKey(userId uint, day time.Time) string {
+	return fmt.Sprintf("%d/%s", userId, day.Format("2006-01-02"))
+}
+
+// Writes one row per gap with the expected, logged and missing hours.
+func WriteTimesheetGapsCsv(w io.Writer, gaps []TimesheetGap) error {
+	cw := csv.NewWriter(w)
+	header := []string{"user", "date", "expected_hours", "logged_hours", "missing_hours"}
+
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+
+	for _, gap := range gaps {
+		row := []string{
+			gap.User.Name,
+			gap.Date.Format("2006-01-02"),
+			gap.ExpectedHours.String(),
+			gap.LoggedHours.String(),
+			gap.MissingHours().String(),
+		}
+
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,224 @@
+package randall
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// The name of a timezone supported by the Harvest API, e.g. EasternTimeUsCanada.
+type Timezone string
+
+// The IANA location of every timezone supported by the Harvest API.
+var harvestTimezoneLocations = map[Timezone]string{
+	InternationalDateLineWest: "Etc/GMT+12",
+	AmericanSamoa:             "Pacific/Pago_Pago",
+	MidwayIsland:              "Pacific/Midway",
+	Hawaii:                    "Pacific/Honolulu",
+	Alaska:                    "America/Juneau",
+	PacificTimeUsCanada:       "America/Los_Angeles",
+	Tijuana:                   "America/Tijuana",
+	Arizona:                   "America/Phoenix",
+	Chihuahua:                 "America/Chihuahua",
+	Mazatlan:                  "America/Mazatlan",
+	MountainTimeUsCanada:      "America/Denver",
+	CentralAmerica:            "America/Guatemala",
+	CentralTimeUsCanada:       "America/Chicago",
+	Guadalajara:               "America/Mexico_City",
+	MexicoCity:                "America/Mexico_City",
+	Monterrey:                 "America/Monterrey",
+	Saskatchewan:              "America/Regina",
+	Bogota:                    "America/Bogota",
+	EasternTimeUsCanada:       "America/New_York",
+	IndianaEast:               "America/Indiana/Indianapolis",
+	Lima:                      "America/Lima",
+	Quito:                     "America/Lima",
+	AtlanticTimeCanada:        "America/Halifax",
+	Caracas:                   "America/Caracas",
+	Georgetown:                "America/Guyana",
+	LaPaz:                     "America/La_Paz",
+	PuertoRico:                "America/Puerto_Rico",
+	Santiago:                  "America/Santiago",
+	Newfoundland:              "America/St_Johns",
+	Brasilia:                  "America/Sao_Paulo",
+	BuenosAires:               "America/Argentina/Buenos_Aires",
+	Greenland:                 "America/Godthab",
+	Montevideo:                "America/Montevideo",
+	MidAtlantic:               "Atlantic/South_Georgia",
+	Azores:                    "Atlantic/Azores",
+	CapeVerdeIs:               "Atlantic/Cape_Verde",
+	Casablanca:                "Africa/Casablanca",
+	Dublin:                    "Europe/Dublin",
+	Edinburgh:                 "Europe/London",
+	Lisbon:                    "Europe/Lisbon",
+	London:                    "Europe/London",
+	Monrovia:                  "Africa/Monrovia",
+	Utc:                       "Etc/UTC",
+	Amsterdam:                 "Europe/Amsterdam",
+	Belgrade:                  "Europe/Belgrade",
+	Berlin:                    "Europe/Berlin",
+	Bern:                      "Europe/Zurich",
+	Bratislava:                "Europe/Bratislava",
+	Brussels:                  "Europe/Brussels",
+	Budapest:                  "Europe/Budapest",
+	Copenhagen:                "Europe/Copenhagen",
+	Ljubljana:                 "Europe/Ljubljana",
+	Madrid:                    "Europe/Madrid",
+	Paris:                     "Europe/Paris",
+	Prague:                    "Europe/Prague",
+	Rome:                      "Europe/Rome",
+	Sarajevo:                  "Europe/Sarajevo",
+	Skopje:                    "Europe/Skopje",
+	Stockholm:                 "Europe/Stockholm",
+	Vienna:                    "Europe/Vienna",
+	Warsaw:                    "Europe/Warsaw",
+	WestCentralAfrica:         "Africa/Algiers",
+	Zagreb:                    "Europe/Zagreb",
+	Zurich:                    "Europe/Zurich",
+	Athens:                    "Europe/Athens",
+	Bucharest:                 "Europe/Bucharest",
+	Cairo:                     "Africa/Cairo",
+	Harare:                    "Africa/Harare",
+	Helsinki:                  "Europe/Helsinki",
+	Jerusalem:                 "Asia/Jerusalem",
+	Kaliningrad:               "Europe/Kaliningrad",
+	Kyiv:                      "Europe/Kiev",
+	Pretoria:                  "Africa/Johannesburg",
+	Riga:                      "Europe/Riga",
+	Sofia:                     "Europe/Sofia",
+	Tallinn:                   "Europe/Tallinn",
+	Vilnius:                   "Europe/Vilnius",
+	Baghdad:                   "Asia/Baghdad",
+	Istanbul:                  "Europe/Istanbul",
+	Kuwait:                    "Asia/Kuwait",
+	Minsk:                     "Europe/Minsk",
+	Moscow:                    "Europe/Moscow",
+	Nairobi:                   "Africa/Nairobi",
+	Riyadh:                    "Asia/Riyadh",
+	StPetersburg:              "Europe/Moscow",
+	Volgograd:                 "Europe/Volgograd",
+	Tehran:                    "Asia/Tehran",
+	AbuDhabi:                  "Asia/Muscat",
+	Baku:                      "Asia/Baku",
+	Muscat:                    "Asia/Muscat",
+	Samara:                    "Europe/Samara",
+	Tbilisi:                   "Asia/Tbilisi",
+	Yerevan:                   "Asia/Yerevan",
+	Kabul:                     "Asia/Kabul",
+	Ekaterinburg:              "Asia/Yekaterinburg",
+	Islamabad:                 "Asia/Karachi",
+	Karachi:                   "Asia/Karachi",
+	Tashkent:                  "Asia/Tashkent",
+	Chennai:                   "Asia/Kolkata",
+	Kolkata:                   "Asia/Kolkata",
+	Mumbai:                    "Asia/Kolkata",
+	NewDelhi:                  "Asia/Kolkata",
+	SriJayawardenepura:        "Asia/Colombo",
+	Kathmandu:                 "Asia/Kathmandu",
+	Almaty:                    "Asia/Almaty",
+	Astana:                    "Asia/Dhaka",
+	Dhaka:                     "Asia/Dhaka",
+	Urumqi:                    "Asia/Urumqi",
+	Rangoon:                   "Asia/Rangoon",
+	Bangkok:                   "Asia/Bangkok",
+	Hanoi:                     "Asia/Bangkok",
+	Jakarta:                   "Asia/Jakarta",
+	Krasnoyarsk:               "Asia/Krasnoyarsk",
+	Novosibirsk:               "Asia/Novosibirsk",
+	Beijing:                   "Asia/Shanghai",
+	Chongqing:                 "Asia/Chongqing",
+	HongKong:                  "Asia/Hong_Kong",
+	Irkutsk:                   "Asia/Irkutsk",
+	KualaLumpur:               "Asia/Kuala_Lumpur",
+	Perth:                     "Australia/Perth",
+	Singapore:                 "Asia/Singapore",
+	Taipei:                    "Asia/Taipei",
+	Ulaanbaatar:               "Asia/Ulaanbaatar",
+	Osaka:                     "Asia/Tokyo",
+	Sapporo:                   "Asia/Tokyo",
+	Seoul:                     "Asia/Seoul",
+	Tokyo:                     "Asia/Tokyo",
+	Yakutsk:                   "Asia/Yakutsk",
+	Adelaide:                  "Australia/Adelaide",
+	Darwin:                    "Australia/Darwin",
+	Brisbane:                  "Australia/Brisbane",
+	Canberra:                  "Australia/Melbourne",
+	Guam:                      "Pacific/Guam",
+	Hobart:                    "Australia/Hobart",
+	Melbourne:                 "Australia/Melbourne",
+	PortMoresby:               "Pacific/Port_Moresby",
+	Sydney:                    "Australia/Sydney",
+	Vladivostok:               "Asia/Vladivostok",
+	Magadan:                   "Asia/Magadan",
+	NewCaledonia:              "Pacific/Noumea",
+	SolomonIs:                 "Pacific/Guadalcanal",
+	Srednekolymsk:             "Asia/Srednekolymsk",
+	Auckland:                  "Pacific/Auckland",
+	Fiji:                      "Pacific/Fiji",
+	Kamchatka:                 "Asia/Kamchatka",
+	MarshallIs:                "Pacific/Majuro",
+	Wellington:                "Pacific/Auckland",
+	ChathamIs:                 "Pacific/Chatham",
+	NukuAlofa:                 "Pacific/Tongatapu",
+	Samoa:                     "Pacific/Apia",
+	TokelauIs:                 "Pacific/Fakaofo",
+}
+
+// Loads the location of a Harvest timezone name, e.g. "Eastern Time (US & Canada)". IANA
+// names such as "America/New_York" are loaded as is.
+func LoadHarvestLocation(timezone string) (*time.Location, error) {
+	name, ok := harvestTimezoneLocations[Timezone(timezone)]
+
+	if !ok {
+		name = timezone
+	}
+
+	loc, err := time.LoadLocation(name)
+
+	if err != nil {
+		return nil, fmt.Errorf("unknown timezone %q: %w", timezone, err)
+	}
+
+	return loc, nil
+}
+
+// Parses the name of a Harvest timezone, ignoring case.
+func ParseTimezone(s string) (Timezone, error) {
+	tz := Timezone(strings.TrimSpace(s))
+
+	if tz.Valid() {
+		return tz, nil
+	}
+
+	for known := range harvestTimezoneLocations {
+		if strings.EqualFold(string(known), string(tz)) {
+			return known, nil
+		}
+	}
+
+	return tz, fmt.Errorf("invalid timezone %q", s)
+}
+
+// Returns whether Harvest supports the timezone.
+func (tz Timezone) Valid() bool {
+	_, ok := harvestTimezoneLocations[tz]
+	return ok
+}
+
+// Loads the location of the timezone, see LoadHarvestLocation.
+func (tz Timezone) Location() (*time.Location, error) {
+	return LoadHarvestLocation(string(tz))
+}
+
+func (tz Timezone) String() string {
+	return string(tz)
+}
+
+func (tz Timezone) MarshalJSON() ([]byte, error) {
+	return marshalEnum(tz)
+}
+
+func (tz *Timezone) UnmarshalJSON(b []byte) (err error) {
+	*tz, err = unmarshalEnum(b, ParseTimezone)
+	return err
+}
@@ -7,12 +7,17 @@ import (
 	"github.com/shopspring/decimal"
 )
 
+// The permissions of a user in Harvest.
+type AccessRole string
+
 const (
-	AccessRoleMember        = "member"
-	AccessRoleManager       = "manager"
-	AccessRoleAdministrator = "administrator"
+	AccessRoleMember        AccessRole = "member"
+	AccessRoleManager       AccessRole = "manager"
+	AccessRoleAdministrator AccessRole = "administrator"
 )
 
+var accessRoles = []AccessRole{AccessRoleMember, AccessRoleManager, AccessRoleAdministrator}
+
 // Encapsulates the Harvest API methods under /users
 type UsersApi struct {
 	baseUrl string
@@ -23,7 +28,7 @@ type CreateUserRequest struct {
 	FirstName                   string           `json:"first_name"`
 	LastName                    string           `json:"last_name"`
 	Email                       string           `json:"email"`
-	Timezone                    *string          `json:"timezone,omitempty"`
+	Timezone                    *Timezone        `json:"timezone,omitempty"`
 	HasAccesToAllFutureProjects *bool            `json:"has_access_to_all_future_projects,omitempty"`
 	IsContractor                *bool            `json:"is_contractor,omitempty"`
 	IsActive                    *bool            `json:"is_active,omitempty"`
@@ -31,14 +36,14 @@ type CreateUserRequest struct {
 	DefaultHourlyRate           *decimal.Decimal `json:"default_hourly_rate,omitempty"`
 	CostRate                    *decimal.Decimal `json:"cost_rate,omitempty"`
 	Roles                       []string         `json:"roles,omitempty"`
-	AccessRoles                 []string         `json:"access_roles,omitempty"`
+	AccessRoles                 []AccessRole     `json:"access_roles,omitempty"`
 }
 
 type UpdateUserRequest struct {
 	FirstName                   *string          `json:"first_name,omitempty"`
 	LastName                    *string          `json:"last_name,omitempty"`
 	Email                       *string          `json:"email,omitempty"`
-	Timezone                    *string          `json:"timezone,omitempty"`
+	Timezone                    *Timezone        `json:"timezone,omitempty"`
 	HasAccesToAllFutureProjects *bool            `json:"has_access_to_all_future_projects,omitempty"`
 	IsContractor                *bool            `json:"is_contractor,omitempty"`
 	IsActive                    *bool            `json:"is_active,omitempty"`
@@ -46,7 +51,7 @@ type UpdateUserRequest struct {
 	DefaultHourlyRate           *decimal.Decimal `json:"default_hourly_rate,omitempty"`
 	CostRate                    *decimal.Decimal `json:"cost_rate,omitempty"`
 	Roles                       []string         `json:"roles,omitempty"`
-	AccessRoles                 []string         `json:"access_roles,omitempty"`
+	AccessRoles                 []AccessRole     `json:"access_roles,omitempty"`
 }
 
 type UpdateAssignedTeammatesRequest struct {
@@ -63,6 +68,85 @@ type CreateCostRateRequest struct {
 	StartDate time.Time       `json:"date,omitempty" layout:"2006-01-02"`
 }
 
+// A user as returned by the Harvest API.
+type User struct {
+	Id                          uint     `json:"id"`
+	FirstName                   string   `json:"first_name"`
+	LastName                    string   `json:"last_name"`
+	Email                       string   `json:"email"`
+	Telephone                   string   `json:"telephone"`
+	Timezone                    Timezone `json:"timezone"`
+	HasAccesToAllFutureProjects bool     `json:"has_access_to_all_future_projects"`
+	IsContractor                bool     `json:"is_contractor"`
+	IsActive                    bool     `json:"is_active"`
+	// The number of hours per week the user is available to work, in seconds.
+	WeeklyCapacity    uint             `json:"weekly_capacity"`
+	DefaultHourlyRate *decimal.Decimal `json:"default_hourly_rate"`
+	CostRate          *decimal.Decimal `json:"cost_rate"`
+	Roles             []string         `json:"roles"`
+	AccessRoles       []AccessRole     `json:"access_roles"`
+	AvatarUrl         string           `json:"avatar_url"`
+	CreatedAt         time.Time        `json:"created_at"`
+	UpdatedAt         time.Time        `json:"updated_at"`
+}
+
+// A teammate assigned to a manager as returned by the Harvest API.
+type Teammate struct {
+	Id        uint   `json:"id"`
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+	Email     string `json:"email"`
+}
+
+// A project assignment of a user as returned by the Harvest API, including the tasks
+// assigned to the project.
+type ProjectAssignment struct {
+	Id               uint                    `json:"id"`
+	IsProjectManager bool                    `json:"is_project_manager"`
+	IsActive         bool                    `json:"is_active"`
+	UseDefaultRates  bool                    `json:"use_default_rates"`
+	Budget           *decimal.Decimal        `json:"budget"`
+	HourlyRate       *decimal.Decimal        `json:"hourly_rate"`
+	Project          ProjectRef              `json:"project"`
+	Client           ClientRef               `json:"client"`
+	TaskAssignments  []ProjectTaskAssignment `json:"task_assignments"`
+	CreatedAt        time.Time               `json:"created_at"`
+	UpdatedAt        time.Time               `json:"updated_at"`
+}
+
+// A task assignment embedded in a ProjectAssignment.
+type ProjectTaskAssignment struct {
+	Id         uint             `json:"id"`
+	Task       ObjectRef        `json:"task"`
+	IsActive   bool             `json:"is_active"`
+	Billable   bool             `json:"billable"`
+	HourlyRate *decimal.Decimal `json:"hourly_rate"`
+	Budget     *decimal.Decimal `json:"budget"`
+}
+
+// Parses an access role, ignoring case.
+func ParseAccessRole(s string) (AccessRole, error) {
+	return parseEnum("access role", accessRoles, s)
+}
+
+// Returns whether the access role is known to Harvest.
+func (r AccessRole) Valid() bool {
+	return containsEnum(accessRoles, r)
+}
+
+func (r AccessRole) String() string {
+	return string(r)
+}
+
+func (r AccessRole) MarshalJSON() ([]byte, error) {
+	return marshalEnum(r)
+}
+
+func (r *AccessRole) UnmarshalJSON(b []byte) (err error) {
+	*r, err = unmarshalEnum(b, ParseAccessRole)
+	return err
+}
+
 func newUsersV2(client *internalClient) UsersApi {
 	return UsersApi{
 		baseUrl: "v2/users",
@@ -80,6 +164,11 @@ func (api UsersApi) AllUsers(params ...HarvestCollectionParams) (HarvestResponse
 	return api.client.doGet(api.baseUrl, getOptionalCollectionParams(params))
 }
 
+// Retrieves every page of users matching params as typed User objects.
+func (api UsersApi) AllUsersPages(params ...HarvestCollectionParams) ([]User, error) {
+	return getAllCollectionPages[User]("users", params, api.AllUsers)
+}
+
 // Retrieves the user with the give UserID. Returns a user object and a 200 OK response code if valid ID provided.
 func (api UsersApi) GetUser(userId uint) (HarvestResponse, error) {
 	return api.client.doGet(fmt.Sprintf("%s/%d", api.baseUrl, userId))
@@ -110,8 +199,16 @@ func (api UsersApi) UnarchiveUser(userId uint) (HarvestResponse, error) {
 	})
 }
 
-func (api UsersApi) GetAssignedTeammates(userId uint) (HarvestResponse, error) {
-	return api.client.doGet(fmt.Sprintf("%s/%d/teammates", api.baseUrl, userId))
+func (api UsersApi) GetAssignedTeammates(userId uint, params ...HarvestCollectionParams) (HarvestResponse, error) {
+	return api.client.doGet(fmt.Sprintf("%s/%d/teammates", api.baseUrl, userId), getOptionalCollectionParams(params))
+}
+
+// Retrieves every page of the teammates assigned to the manager with the given UserID as
+// typed Teammate objects.
+func (api UsersApi) GetAssignedTeammatePages(userId uint, params ...HarvestCollectionParams) ([]Teammate, error) {
+	return getAllCollectionPages[Teammate]("teammates", params, func(params ...HarvestCollectionParams) (HarvestResponse, error) {
+		return api.GetAssignedTeammates(userId, params...)
+	})
 }
 
 func (api UsersApi) UpdateAssignedTeammates(userId uint, teammateIds UpdateAssignedTeammatesRequest) (HarvestResponse, error) {
@@ -160,3 +257,9 @@ func (api UsersApi) GetMyActiveProjectAssignments(params ...HarvestCollectionPar
 		getOptionalCollectionParams(params),
 	)
 }
+
+// Retrieves every page of the authenticated user's active project assignments as typed
+// ProjectAssignment objects.
+func (api UsersApi) GetMyActiveProjectAssignmentPages(params ...HarvestCollectionParams) ([]ProjectAssignment, error) {
+	return getAllCollectionPages[ProjectAssignment]("project_assignments", params, api.GetMyActiveProjectAssignments)
+}
@@ -0,0 +1,297 @@
+package randall
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// The capacity of a user over a period and the hours they tracked in it.
+type UserUtilization struct {
+	User ObjectRef `json:"user"`
+	// The names of the user's roles.
+	Roles []string `json:"roles"`
+	// The names of the teams the user is part of, see UtilizationReport.Teams.
+	Teams []string `json:"teams"`
+	// The user's weekly capacity prorated to the working days of the period.
+	CapacityHours decimal.Decimal `json:"capacity_hours"`
+	TrackedHours  decimal.Decimal `json:"tracked_hours"`
+	BillableHours decimal.Decimal `json:"billable_hours"`
+	// The tracked hours as a percentage of the capacity, zero without capacity.
+	Utilization decimal.Decimal `json:"utilization"`
+	// The billable hours as a percentage of the capacity, zero without capacity.
+	BillableUtilization decimal.Decimal `json:"billable_utilization"`
+}
+
+// The summed capacity and hours of a group of users.
+type UtilizationGroup struct {
+	Name                string          `json:"name"`
+	Users               int             `json:"users"`
+	CapacityHours       decimal.Decimal `json:"capacity_hours"`
+	TrackedHours        decimal.Decimal `json:"tracked_hours"`
+	BillableHours       decimal.Decimal `json:"billable_hours"`
+	Utilization         decimal.Decimal `json:"utilization"`
+	BillableUtilization decimal.Decimal `json:"billable_utilization"`
+}
+
+// The utilization of every user over a period, grouped by role and team.
+type UtilizationReport struct {
+	From time.Time `json:"from"`
+	To   time.Time `json:"to"`
+	// The number of working days in the period capacity is prorated to, holidays excluded.
+	WorkingDays int               `json:"working_days"`
+	Users       []UserUtilization `json:"users"`
+	// One group per role. Users with several roles are part of each of their groups, users
+	// without a role only of Total.
+	Roles []UtilizationGroup `json:"roles"`
+	// One group per manager, named after them, made of the manager and the teammates
+	// assigned to them.
+	Teams []UtilizationGroup `json:"teams"`
+	// Every user of the report.
+	Total UtilizationGroup `json:"total"`
+}
+
+// Configures the working days of a utilization report.
+type UtilizationOptions struct {
+	// The days nobody is expected to work on. Optional.
+	Holidays HolidayCalendar
+	// The days of the week users work on. Defaults to Monday to Friday.
+	WorkingDays []time.Weekday
+}
+
+// Builds the utilization report of the period [from, to] for every active user and every
+// inactive user who tracked time in it. Capacity assumes a user's weekly capacity is spread
+// evenly over the working days of the week, and nothing is expected on holidays.
+func BuildUtilizationReport(client *HarvestClient, from, to time.Time, opts UtilizationOptions) (UtilizationReport, error) {
+	days := workingDays(from, to, opts.WorkingDays, opts.Holidays)
+	report := UtilizationReport{From: from, To: to, WorkingDays: len(days)}
+
+	users, err := client.Users.AllUsersPages()
+
+	if err != nil {
+		return report, err
+	}
+
+	roles, err := client.Roles.GetAllPages()
+
+	if err != nil {
+		return report, err
+	}
+
+	entries, err := client.TimeEntries.GetAllPages(GetTimeEntriesParams{FromDate: OptionalTime(from), ToDate: OptionalTime(to)})
+
+	if err != nil {
+		return report, err
+	}
+
+	byUser := make(map[uint]*UserUtilization)
+
+	for _, e := range entries {
+		u, ok := byUser[e.User.Id]
+
+		if !ok {
+			u = &UserUtilization{User: e.User}
+			byUser[e.User.Id] = u
+		}
+
+		u.TrackedHours = u.TrackedHours.Add(e.Hours)
+
+		if e.Billable {
+			u.BillableHours = u.BillableHours.Add(e.Hours)
+		}
+	}
+
+	periodDays := decimal.NewFromInt(int64(len(days)))
+	weekDays := decimal.NewFromInt(int64(len(workingWeekdays(opts.WorkingDays))))
+	var managers []User
+
+	for _, user := range users {
+		u, tracked := byUser[user.Id]
+
+		if !user.IsActive && !tracked {
+			continue
+		}
+
+		if !tracked {
+			u = &UserUtilization{}
+			byUser[user.Id] = u
+		}
+
+		u.User = ObjectRef{Id: user.Id, Name: user.FirstName + " " + user.LastName}
+		u.CapacityHours = decimal.NewFromInt(int64(user.WeeklyCapacity)).
+			Div(secondsPerHour).Mul(periodDays).Div(weekDays).Round(2)
+
+		if user.IsActive && containsEnum(user.AccessRoles, AccessRoleManager) {
+			managers = append(managers, user)
+		}
+	}
+
+	for _, role := range roles {
+		group := UtilizationGroup{Name: role.Name}
+
+		for _, id := range role.UserIds {
+			if u, ok := byUser[id]; ok {
+				u.Roles = append(u.Roles, role.Name)
+				group.add(*u)
+			}
+		}
+
+		report.Roles = append(report.Roles, group.withPercentages())
+	}
+
+	for _, manager := range managers {
+		teammates, err := client.Users.GetAssignedTeammatePages(manager.Id)
+
+		if err != nil {
+			return report, err
+		}
+
+		name := manager.FirstName + " " + manager.LastName
+		group := UtilizationGroup{Name: name}
+		members := []uint{manager.Id}
+
+		for _, t := range teammates {
+			members = append(members, t.Id)
+		}
+
+		for _, id := range members {
+			if u, ok := byUser[id]; ok {
+				u.Teams = append(u.Teams, name)
+				group.add(*u)
+			}
+		}
+
+		report.Teams = append(report.Teams, group.withPercentages())
+	}
+
+	report.Total.Name = "Total"
+
+	for _, u := range byUser {
+		u.Utilization = percentageOf(u.TrackedHours, u.CapacityHours)
+		u.BillableUtilization = percentageOf(u.BillableHours, u.CapacityHours)
+		report.Users = append(report.Users, *u)
+		report.Total.add(*u)
+	}
+
+	report.Total = report.Total.withPercentages()
+
+	sort.Slice(report.Users, func(i, j int) bool {
+		return report.Users[i].User.Name < report.Users[j].User.Name
+	})
+
+	for _, groups := range [][]UtilizationGroup{report.Roles, report.Teams} {
+		sort.Slice(groups, func(i, j int) bool {
+			return groups[i].Name < groups[j].Name
+		})
+	}
+
+	return report, nil
+}
+
+func (g *UtilizationGroup) add(u UserUtilization) {
+	g.Users++
+	g.CapacityHours = g.CapacityHours.Add(u.CapacityHours)
+	g.TrackedHours = g.TrackedHours.Add(u.TrackedHours)
+	g.BillableHours = g.BillableHours.Add(u.BillableHours)
+}
+
+func (g UtilizationGroup) withPercentages() UtilizationGroup {
+	g.Utilization = percentageOf(g.TrackedHours, g.CapacityHours)
+	g.BillableUtilization = percentageOf(g.BillableHours, g.CapacityHours)
+	return g
+}
+
+// Writes one row per user with their capacity, hours and utilization percentages.
+func (r UtilizationReport) WriteCsv(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	header := []string{"user", "roles", "teams", "capacity_hours", "tracked_hours", "billable_hours", "utilization", "billable_utilization"}
+
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+
+	for _, u := range r.Users {
+		row := []string{
+			u.User.Name,
+			strings.Join(u.Roles, "; "),
+			strings.Join(u.Teams, "; "),
+			u.CapacityHours.String(),
+			u.TrackedHours.String(),
+			u.BillableHours.String(),
+			u.Utilization.StringFixed(2),
+			u.BillableUtilization.StringFixed(2),
+		}
+
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// Writes one row per role and team, and a total row, with their summed capacity, hours and
+// utilization percentages.
+func (r UtilizationReport) WriteGroupsCsv(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	header := []string{"group", "name", "users", "capacity_hours", "tracked_hours", "billable_hours", "utilization", "billable_utilization"}
+
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+
+	write := func(kind string, g UtilizationGroup) error {
+		return cw.Write([]string{
+			kind,
+			g.Name,
+			strconv.Itoa(g.Users),
+			g.CapacityHours.String(),
+			g.TrackedHours.String(),
+			g.BillableHours.String(),
+			g.Utilization.StringFixed(2),
+			g.BillableUtilization.StringFixed(2),
+		})
+	}
+
+	for _, g := range r.Roles {
+		if err := write("role", g); err != nil {
+			return err
+		}
+	}
+
+	for _, g := range r.Teams {
+		if err := write("team", g); err != nil {
+			return err
+		}
+	}
+
+	if err := write("total", r.Total); err != nil {
+		return err
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// Writes the report as indented JSON.
+func (r UtilizationReport) WriteJson(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+// Returns part as a percentage of whole rounded to 2 decimal places, or zero if whole is.
+func percentageOf(part, whole decimal.Decimal) decimal.Decimal {
+	if whole.IsZero() {
+		return decimal.Zero
+	}
+
+	return part.Mul(hundred).Div(whole).Round(2)
+}
@@ -0,0 +1,702 @@
+package randall
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// A problem with a field of a request.
+type FieldError struct {
+	// The JSON name of the field, e.g. "line_items[0].kind".
+	Field   string
+	Message string
+}
+
+// Returned when a request fails validation before it is sent, describing every invalid
+// field. Validation is disabled with WithoutValidation.
+type ValidationError struct {
+	// The type of the request, e.g. "CreateProjectRequest".
+	Request string
+	Fields  []FieldError
+}
+
+// The payment terms of an invoice supported by the Harvest API.
+var invoicePaymentTerms = []string{"upon receipt", "net 15", "net 30", "net 45", "net 60", "custom"}
+
+// Start and end times as accepted by the Harvest API, e.g. "8:00am".
+var harvestClockTimeRegex = regexp.MustCompile(`(?i)^(0?[1-9]|1[0-2]):[0-5][0-9] ?[ap]m$`)
+
+func (e *ValidationError) Error() string {
+	problems := make([]string, len(e.Fields))
+
+	for i, f := range e.Fields {
+		problems[i] = f.Field + " " + f.Message
+	}
+
+	return fmt.Sprintf("invalid %s: %s", e.Request, strings.Join(problems, "; "))
+}
+
+// Collects the field errors of a request.
+type validation struct {
+	request string
+	fields  []FieldError
+}
+
+func newValidation(request interface{}) *validation {
+	return &validation{request: reflect.TypeOf(request).Name()}
+}
+
+func (v *validation) add(field, format string, args ...interface{}) {
+	v.fields = append(v.fields, FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+// Adds the field errors of a nested request, prefixing their fields with field.
+func (v *validation) nested(field string, err error) {
+	var invalid *ValidationError
+
+	if errors.As(err, &invalid) {
+		for _, f := range invalid.Fields {
+			v.fields = append(v.fields, FieldError{Field: field + "." + f.Field, Message: f.Message})
+		}
+	} else if err != nil {
+		v.add(field, "%s", err)
+	}
+}
+
+func (v *validation) id(field string, id uint) {
+	if id == 0 {
+		v.add(field, "is required")
+	}
+}
+
+func (v *validation) text(field string, s string) {
+	if strings.TrimSpace(s) == "" {
+		v.add(field, "is required")
+	}
+}
+
+// Checks a field that may be omitted but not set to blank.
+func (v *validation) optionalText(field string, s *string) {
+	if s != nil {
+		v.text(field, *s)
+	}
+}
+
+func (v *validation) email(field string, s string) {
+	if address, err := mail.ParseAddress(s); err != nil || address.Address != s {
+		v.add(field, "is not a valid email address: %q", s)
+	}
+}
+
+func (v *validation) date(field string, t time.Time) {
+	if t.IsZero() {
+		v.add(field, "is required")
+	}
+}
+
+func (v *validation) dateRange(fromField string, from *time.Time, toField string, to *time.Time) {
+	if from != nil && to != nil && to.Before(*from) {
+		v.add(toField, "is before %s", fromField)
+	}
+}
+
+func (v *validation) nonNegative(field string, d *decimal.Decimal) {
+	if d != nil && d.IsNegative() {
+		v.add(field, "must not be negative, got %s", d)
+	}
+}
+
+func (v *validation) percentage(field string, d *decimal.Decimal) {
+	if d != nil && (d.IsNegative() || d.GreaterThan(hundred)) {
+		v.add(field, "must be a percentage between 0 and 100, got %s", d)
+	}
+}
+
+func (v *validation) currency(field string, c *Currency) {
+	if c != nil && !c.Valid() {
+		v.add(field, "is not a currency supported by Harvest: %q", string(*c))
+	}
+}
+
+func (v *validation) clockTime(field string, s *string) {
+	if s != nil && !harvestClockTimeRegex.MatchString(strings.TrimSpace(*s)) {
+		v.add(field, "must be a time like 8:00am, got %q", *s)
+	}
+}
+
+func (v *validation) paymentTerm(field string, s *string) {
+	if s != nil && !containsEnum(invoicePaymentTerms, *s) {
+		v.add(field, "must be one of %s, got %q", strings.Join(invoicePaymentTerms, ", "), *s)
+	}
+}
+
+func (v *validation) exclusive(field string, set bool, otherField string, otherSet bool) {
+	if set && otherSet {
+		v.add(field, "cannot be combined with %s", otherField)
+	}
+}
+
+func (v *validation) err() error {
+	if len(v.fields) == 0 {
+		return nil
+	}
+
+	return &ValidationError{Request: v.request, Fields: v.fields}
+}
+
+// Validates the body of a request before it is sent, unless validation is disabled.
+func (client *internalClient) validateBody(body interface{}) error {
+	if validator, ok := body.(interface{ Validate() error }); ok && !client.skipValidation {
+		return validator.Validate()
+	}
+
+	return nil
+}
+
+func (r CreateClientRequest) Validate() error {
+	v := newValidation(r)
+	v.text("name", r.Name)
+	v.currency("currency", r.Currency)
+	return v.err()
+}
+
+func (r PatchClientRequest) Validate() error {
+	v := newValidation(r)
+	v.optionalText("name", r.Name)
+	v.currency("currency", r.Currency)
+	return v.err()
+}
+
+func (r UpdateCompanyRequest) Validate() error {
+	return nil
+}
+
+func (r MessageRecipient) Validate() error {
+	v := newValidation(r)
+	v.email("email", r.Email)
+	return v.err()
+}
+
+func (r CreateContactRequest) Validate() error {
+	v := newValidation(r)
+	v.id("client_id", r.ClientId)
+	v.text("first_name", r.Firstname)
+
+	if r.Email != nil {
+		v.email("email", *r.Email)
+	}
+
+	return v.err()
+}
+
+func (r PatchContactRequest) Validate() error {
+	v := newValidation(r)
+	v.optionalText("first_name", r.Firstname)
+
+	if r.ClientId != nil {
+		v.id("client_id", *r.ClientId)
+	}
+
+	if r.Email != nil {
+		v.email("email", *r.Email)
+	}
+
+	return v.err()
+}
+
+func (r CreateEstimateRequest) Validate() error {
+	v := newValidation(r)
+	v.id("client_id", r.ClientId)
+	v.percentage("tax", r.Tax)
+	v.percentage("tax2", r.Tax2)
+	v.percentage("discount", r.Discount)
+	v.currency("currency", r.Currency)
+
+	for i, item := range r.LineItems {
+		v.nested(fmt.Sprintf("line_items[%d]", i), item.Validate())
+	}
+
+	return v.err()
+}
+
+func (r CreateEstimateLineItemRequest) Validate() error {
+	v := newValidation(r)
+	validateLineItemKind(v, &r.Kind)
+	return v.err()
+}
+
+func (r UpdateEstimateRequest) Validate() error {
+	v := newValidation(r)
+	v.percentage("tax", r.Tax)
+	v.percentage("tax2", r.Tax2)
+	v.percentage("discount", r.Discount)
+	v.currency("currency", r.Currency)
+
+	if r.ClientId != nil {
+		v.id("client_id", *r.ClientId)
+	}
+
+	for i, item := range r.LineItems {
+		v.nested(fmt.Sprintf("line_items[%d]", i), item.Validate())
+	}
+
+	return v.err()
+}
+
+func (r UpdateEstimateLineItemRequest) Validate() error {
+	v := newValidation(r)
+	validateUpdateLineItem(v, r.Id, r.Kind, r.Destroy)
+	return v.err()
+}
+
+func (r CreateEstimateMessageRequest) Validate() error {
+	v := newValidation(r)
+
+	if r.EventType != nil {
+		if _, ok := estimateTransitions[EstimateEvent(*r.EventType)]; !ok {
+			v.add("event_type", "is not an estimate event: %q", *r.EventType)
+		}
+	} else {
+		validateRecipients(v, r.Recipients)
+	}
+
+	return v.err()
+}
+
+func (r CreateExpenseRequest) Validate() error {
+	v := newValidation(r)
+	v.id("project_id", r.ProjectId)
+	v.id("expense_category_id", r.ExpenseCategoryId)
+	v.date("spent_date", r.SpentDate)
+	v.nonNegative("total_cost", r.TotalCost)
+	v.exclusive("units", r.Units != nil, "total_cost", r.TotalCost != nil)
+	return v.err()
+}
+
+func (r UpdateExpenseRequest) Validate() error {
+	v := newValidation(r)
+	v.nonNegative("total_cost", r.TotalCost)
+	v.exclusive("units", r.Units != nil, "total_cost", r.TotalCost != nil)
+	v.exclusive("receipt", r.Receipt != nil, "delete_receipt", r.DeleteReceipt != nil && *r.DeleteReceipt)
+	return v.err()
+}
+
+func (r CreateExpenseCategoryRequest) Validate() error {
+	v := newValidation(r)
+	v.text("name", r.Name)
+	v.nonNegative("unit_price", r.UnitPrice)
+
+	if (r.UnitName == nil) != (r.UnitPrice == nil) {
+		v.add("unit_name", "and unit_price must be set together")
+	}
+
+	return v.err()
+}
+
+func (r UpdateExpenseCategoryRequest) Validate() error {
+	v := newValidation(r)
+	v.optionalText("name", r.Name)
+	v.nonNegative("unit_price", r.UnitPrice)
+	return v.err()
+}
+
+func (r CreateFreeFormInvoiceRequest) Validate() error {
+	v := newValidation(r)
+	v.id("client_id", r.ClientId)
+	validateInvoiceFields(v, r.Tax, r.Tax2, r.Discount, r.Currency, r.IssueDate, r.DueDate, r.PaymentTerm)
+
+	for i, item := range r.LineItems {
+		v.nested(fmt.Sprintf("line_items[%d]", i), item.Validate())
+	}
+
+	return v.err()
+}
+
+func (r CreateInvoiceFromTrackedTimeAndExpenseRequest) Validate() error {
+	v := newValidation(r)
+	v.id("client_id", r.ClientId)
+	validateInvoiceFields(v, r.Tax, r.Tax2, r.Discount, r.Currency, r.IssueDate, r.DueDate, r.PaymentTerm)
+
+	if r.LineItemsImport == nil {
+		v.add("line_items_import", "is required")
+	} else {
+		v.nested("line_items_import", r.LineItemsImport.Validate())
+	}
+
+	return v.err()
+}
+
+func (r CreateInvoiceLineItemRequest) Validate() error {
+	v := newValidation(r)
+	validateLineItemKind(v, &r.Kind)
+
+	if r.ProjectId != nil {
+		v.id("project_id", *r.ProjectId)
+	}
+
+	return v.err()
+}
+
+func (r CreateLineItemsImportRequest) Validate() error {
+	v := newValidation(r)
+
+	if len(r.ProjectIds) == 0 {
+		v.add("project_ids", "is required")
+	}
+
+	if r.Time == nil && r.Expenses == nil {
+		v.add("time", "or expenses is required")
+	}
+
+	if r.Time != nil {
+		v.nested("time", r.Time.Validate())
+	}
+
+	if r.Expenses != nil {
+		v.nested("expenses", r.Expenses.Validate())
+	}
+
+	return v.err()
+}
+
+func (r TimeImport) Validate() error {
+	v := newValidation(r)
+
+	if r.SummaryType == "" {
+		v.add("summary_type", "is required")
+	} else if !r.SummaryType.Valid() || r.SummaryType == InvoiceExpenseSummaryByCategory {
+		v.add("summary_type", "must be one of project, task, people or detailed, got %q", string(r.SummaryType))
+	}
+
+	v.dateRange("from", r.From, "to", r.To)
+	return v.err()
+}
+
+func (r ExpensesImport) Validate() error {
+	v := newValidation(r)
+
+	if r.SummaryType == "" {
+		v.add("summary_type", "is required")
+	} else if !r.SummaryType.Valid() || r.SummaryType == InvoiceTimeSummaryByTask {
+		v.add("summary_type", "must be one of project, category, people or detailed, got %q", string(r.SummaryType))
+	}
+
+	v.dateRange("from", r.From, "to", r.To)
+	return v.err()
+}
+
+func (r UpdateInvoiceRequest) Validate() error {
+	v := newValidation(r)
+	validateInvoiceFields(v, r.Tax, r.Tax2, r.Discount, r.Currency, r.IssueDate, r.DueDate, r.PaymentTerm)
+
+	if r.ClientId != nil {
+		v.id("client_id", *r.ClientId)
+	}
+
+	for i, item := range r.LineItems {
+		v.nested(fmt.Sprintf("line_items[%d]", i), item.Validate())
+	}
+
+	return v.err()
+}
+
+func (r UpdateInvoiceLineItemRequest) Validate() error {
+	v := newValidation(r)
+	validateUpdateLineItem(v, r.Id, r.Kind, r.Destroy)
+	return v.err()
+}
+
+func (r CreateInvoiceMessageRequest) Validate() error {
+	v := newValidation(r)
+
+	if r.EventType != nil {
+		if _, ok := invoiceTransitions[InvoiceEvent(*r.EventType)]; !ok {
+			v.add("event_type", "is not an invoice event: %q", *r.EventType)
+		}
+	} else {
+		validateRecipients(v, r.Recipients)
+	}
+
+	return v.err()
+}
+
+func (r CreateInvoicePaymentRequest) Validate() error {
+	v := newValidation(r)
+
+	if !r.Amount.IsPositive() {
+		v.add("amount", "must be positive, got %s", r.Amount)
+	}
+
+	v.exclusive("paid_at", r.PaidAt != nil, "paid_date", r.PaidDate != nil)
+	return v.err()
+}
+
+func (r CreateProjectRequest) Validate() error {
+	v := newValidation(r)
+	v.id("client_id", r.ClientId)
+	v.text("name", r.Name)
+
+	if r.BillBy == "" {
+		v.add("bill_by", "is required")
+	} else if !r.BillBy.Valid() {
+		v.add("bill_by", "must be one of Project, Tasks, People or none, got %q", string(r.BillBy))
+	}
+
+	if r.BudgetBy == "" {
+		v.add("budget_by", "is required")
+	} else if !r.BudgetBy.Valid() {
+		v.add("budget_by", "must be one of project, project_cost, task, task_fees, person or none, got %q", string(r.BudgetBy))
+	}
+
+	validateProjectFields(v, r.HourlyRate, r.Budget, r.CostBudget, r.Fee, r.OverBudgetNotificationPercentage)
+	validateProjectDates(v, r.StartsOn, r.EndsOn)
+	return v.err()
+}
+
+func (r UpdateProjectRequest) Validate() error {
+	v := newValidation(r)
+	v.optionalText("name", r.Name)
+
+	if r.ClientId != nil {
+		v.id("client_id", *r.ClientId)
+	}
+
+	if r.BillBy != nil && !r.BillBy.Valid() {
+		v.add("bill_by", "must be one of Project, Tasks, People or none, got %q", string(*r.BillBy))
+	}
+
+	if r.BudgetBy != nil && !r.BudgetBy.Valid() {
+		v.add("budget_by", "must be one of project, project_cost, task, task_fees, person or none, got %q", string(*r.BudgetBy))
+	}
+
+	validateProjectFields(v, r.HourlyRate, r.Budget, r.CostBudget, r.Fee, r.OverBudgetNotificationPercentage)
+	validateProjectDates(v, r.StartsOn, r.EndsOn)
+	return v.err()
+}
+
+func (r CreateUserAssignmentRequest) Validate() error {
+	v := newValidation(r)
+	v.id("user_id", r.UserId)
+	validateAssignmentRates(v, r.UseDefaultRates, r.HourlyRate, r.Budget)
+	return v.err()
+}
+
+func (r PatchUserAssignmentRequest) Validate() error {
+	v := newValidation(r)
+	validateAssignmentRates(v, r.UseDefaultRates, r.HourlyRate, r.Budget)
+	return v.err()
+}
+
+func (r CreateTaskAssignmentRequest) Validate() error {
+	v := newValidation(r)
+	v.id("task_id", r.TaskId)
+	v.nonNegative("hourly_rate", r.HourlyRate)
+	v.nonNegative("budget", r.Budget)
+	return v.err()
+}
+
+func (r PatchTaskAssignmentRequest) Validate() error {
+	v := newValidation(r)
+	v.nonNegative("hourly_rate", r.HourlyRate)
+	v.nonNegative("budget", r.Budget)
+	return v.err()
+}
+
+func (r CreateRoleRequest) Validate() error {
+	v := newValidation(r)
+	v.text("name", r.Name)
+	return v.err()
+}
+
+func (r UpdateRoleRequest) Validate() error {
+	v := newValidation(r)
+	v.optionalText("name", r.Name)
+	return v.err()
+}
+
+func (r CreateTaskRequest) Validate() error {
+	v := newValidation(r)
+	v.text("name", r.Name)
+	v.nonNegative("default_hourly_rate", r.DefaultHourlyRate)
+	return v.err()
+}
+
+func (r UpdateTaskRequest) Validate() error {
+	v := newValidation(r)
+	v.optionalText("name", r.Name)
+	v.nonNegative("default_hourly_rate", r.DefaultHourlyRate)
+	return v.err()
+}
+
+func (r CreateTimeEntryViaDurationRequest) Validate() error {
+	v := newValidation(r)
+	v.id("project_id", r.ProjectId)
+	v.id("task_id", r.TaskId)
+	v.date("spent_date", r.SpentDate)
+	validateHours(v, r.Hours)
+	return v.err()
+}
+
+func (r CreateTimeEntryViaStartEndRequest) Validate() error {
+	v := newValidation(r)
+	v.id("project_id", r.ProjectId)
+	v.id("task_id", r.TaskId)
+	v.date("spent_date", r.SpentDate)
+	v.clockTime("started_time", r.StartedTime)
+	v.clockTime("ended_time", r.EndTime)
+
+	if r.EndTime != nil && r.StartedTime == nil {
+		v.add("ended_time", "requires started_time")
+	}
+
+	return v.err()
+}
+
+func (r UpdateTimeEntryRequest) Validate() error {
+	v := newValidation(r)
+
+	if r.ProjectId != nil {
+		v.id("project_id", *r.ProjectId)
+	}
+
+	if r.TaskId != nil {
+		v.id("task_id", *r.TaskId)
+	}
+
+	v.clockTime("started_time", r.StartedTime)
+	v.clockTime("ended_time", r.EndTime)
+	validateHours(v, r.Hours)
+	return v.err()
+}
+
+func (r CreateUserRequest) Validate() error {
+	v := newValidation(r)
+	v.text("first_name", r.FirstName)
+	v.text("last_name", r.LastName)
+	v.email("email", r.Email)
+	validateUserFields(v, r.Timezone, r.AccessRoles, r.DefaultHourlyRate, r.CostRate)
+	return v.err()
+}
+
+func (r UpdateUserRequest) Validate() error {
+	v := newValidation(r)
+	v.optionalText("first_name", r.FirstName)
+	v.optionalText("last_name", r.LastName)
+
+	if r.Email != nil {
+		v.email("email", *r.Email)
+	}
+
+	validateUserFields(v, r.Timezone, r.AccessRoles, r.DefaultHourlyRate, r.CostRate)
+	return v.err()
+}
+
+func (r UpdateAssignedTeammatesRequest) Validate() error {
+	return nil
+}
+
+func (r CreateBillableRateRequest) Validate() error {
+	v := newValidation(r)
+	v.nonNegative("amount", &r.Amount)
+	return v.err()
+}
+
+func (r CreateCostRateRequest) Validate() error {
+	v := newValidation(r)
+	v.nonNegative("amount", &r.Amount)
+	return v.err()
+}
+
+func validateLineItemKind(v *validation, kind *LineItemKind) {
+	if kind != nil && !kind.Valid() {
+		if *kind == "" {
+			v.add("kind", "is required")
+		} else {
+			v.add("kind", "must not be padded with whitespace, got %q", string(*kind))
+		}
+	}
+}
+
+// Line items without an id are created, so need a kind. Only existing line items can be
+// destroyed.
+func validateUpdateLineItem(v *validation, id *uint, kind *LineItemKind, destroy *bool) {
+	if id == nil {
+		if kind == nil {
+			v.add("kind", "is required for new line items")
+		}
+
+		if destroy != nil && *destroy {
+			v.add("_destroy", "requires id")
+		}
+	}
+
+	validateLineItemKind(v, kind)
+}
+
+func validateRecipients(v *validation, recipients []MessageRecipient) {
+	if len(recipients) == 0 {
+		v.add("recipients", "is required")
+	}
+
+	for i, recipient := range recipients {
+		v.nested(fmt.Sprintf("recipients[%d]", i), recipient.Validate())
+	}
+}
+
+func validateInvoiceFields(v *validation, tax, tax2, discount *decimal.Decimal, currency *Currency, issueDate, dueDate *time.Time, paymentTerm *string) {
+	v.percentage("tax", tax)
+	v.percentage("tax2", tax2)
+	v.percentage("discount", discount)
+	v.currency("currency", currency)
+	v.dateRange("issue_date", issueDate, "due_date", dueDate)
+	v.paymentTerm("payment_term", paymentTerm)
+}
+
+func validateProjectFields(v *validation, hourlyRate, budget, costBudget, fee, notificationPercentage *decimal.Decimal) {
+	v.nonNegative("hourly_rate", hourlyRate)
+	v.nonNegative("budget", budget)
+	v.nonNegative("cost_budget", costBudget)
+	v.nonNegative("fee", fee)
+	v.percentage("over_budget_notification_percentage", notificationPercentage)
+}
+
+func validateProjectDates(v *validation, startsOn, endsOn time.Time) {
+	if !startsOn.IsZero() && !endsOn.IsZero() && endsOn.Before(startsOn) {
+		v.add("ends_on", "is before starts_on")
+	}
+}
+
+// Assignments using the default rates of their project can't set their own.
+func validateAssignmentRates(v *validation, useDefaultRates *bool, hourlyRate, budget *decimal.Decimal) {
+	v.exclusive("hourly_rate", hourlyRate != nil, "use_default_rates", useDefaultRates != nil && *useDefaultRates)
+	v.nonNegative("hourly_rate", hourlyRate)
+	v.nonNegative("budget", budget)
+}
+
+func validateHours(v *validation, hours *decimal.Decimal) {
+	if hours != nil && (hours.IsNegative() || hours.GreaterThan(decimal.NewFromInt(24))) {
+		v.add("hours", "must be between 0 and 24, got %s", hours)
+	}
+}
+
+func validateUserFields(v *validation, timezone *Timezone, accessRoles []AccessRole, defaultHourlyRate, costRate *decimal.Decimal) {
+	if timezone != nil && !timezone.Valid() {
+		v.add("timezone", "is not a timezone supported by Harvest: %q", string(*timezone))
+	}
+
+	for i, role := range accessRoles {
+		if !role.Valid() {
+			v.add(fmt.Sprintf("access_roles[%d]", i), "must be one of member, manager or administrator, got %q", string(role))
+		}
+	}
+
+	v.nonNegative("default_hourly_rate", defaultHourlyRate)
+	v.nonNegative("cost_rate", costRate)
+}